@@ -0,0 +1,368 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package changelog renders the changelog of a git range from the commit
+// trailers described in commitlint/grammar.md:
+//
+//   - without a Changelog trailer, the entry is the subject;
+//   - "Changelog: None" omits the commit;
+//   - "Changelog: Commit" (or "All") adds the body below the subject;
+//   - "Changelog: <sentence>" replaces the subject, and "Changelog(<type>):"
+//     also files the entry under another type;
+//   - "Deprecation:" and "BREAKING CHANGE:" have their own sections;
+//   - "Ticket:" is linked, and listed at the end;
+//   - "Cancel-changelog: <sha>" removes the entries of an earlier commit.
+//
+// The grouping is the same as in utils/cliff.toml.
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/mendersoftware/mendertesting/commitlint"
+	"github.com/mendersoftware/mendertesting/internal/git"
+)
+
+// TicketURL is the prefix of ticket links.
+const TicketURL = "https://northerntech.atlassian.net/browse/"
+
+// Commit is a commit to include in the changelog.
+type Commit struct {
+	SHA     string
+	Message string
+}
+
+// Entry is a line in the changelog.
+type Entry struct {
+	Commit string
+	// Type is the commit type, or the type of a typed Changelog trailer.
+	Type  string
+	Scope string
+	// Line is the subject, or the sentence of the Changelog trailer.
+	Line string
+	// Body is only set for "Changelog: Commit".
+	Body    string
+	Tickets []string
+	// Breaking is the migration detail of a BREAKING CHANGE trailer.
+	Breaking string
+}
+
+// Group is a section of entries.
+type Group struct {
+	Title   string
+	Entries []Entry
+}
+
+// Changelog is the changelog of a range of commits.
+type Changelog struct {
+	Breaking     []Entry
+	Deprecations []string
+	// Groups are in the order they are rendered, and never empty.
+	Groups []Group
+	// Tickets are all tickets referenced, without duplicates.
+	Tickets []string
+}
+
+// The groups, in the order they are rendered.
+const (
+	GroupSecurity     = "Security"
+	GroupFeatures     = "New features"
+	GroupImprovements = "Improvements"
+	GroupFixes        = "Bug fixes"
+	GroupDependencies = "Dependency updates"
+)
+
+var groupOrder = []string{
+	GroupSecurity, GroupFeatures, GroupImprovements, GroupFixes, GroupDependencies,
+}
+
+var (
+	cveRegexp  = regexp.MustCompile(`CVE-\d{4}-\d+`)
+	bumpRegexp = regexp.MustCompile(`^chore(: bump|\(deps)`)
+)
+
+// group returns the group of an entry, or "" if it is not in the changelog.
+func group(e Entry, msg *commitlint.Message) string {
+	switch e.Type {
+	case "feat":
+		return GroupFeatures
+	case "fix":
+		return GroupFixes
+	case "perf", "refactor":
+		return GroupImprovements
+	}
+	trailers := make([]string, 0, len(msg.Trailers))
+	for _, t := range msg.Trailers {
+		trailers = append(trailers, t.Value)
+	}
+	text := strings.Join(append([]string{msg.Header, msg.Body}, trailers...), "\n")
+	if cveRegexp.MatchString(text) {
+		return GroupSecurity
+	}
+	if bumpRegexp.MatchString(msg.Header) {
+		return GroupDependencies
+	}
+	return ""
+}
+
+// Generate returns the changelog of the non-merge commits in rangeSpec,
+// which is given to git log after splitting it on white space.
+func Generate(ctx context.Context, repoDir, rangeSpec string) (*Changelog, error) {
+	args := append([]string{"log", "--reverse", "--no-merges", "--format=%H%x00%B%x1e"},
+		strings.Fields(rangeSpec)...)
+	output, err := git.Output(ctx, repoDir, args...)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x00", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		commits = append(commits, Commit{SHA: fields[0], Message: fields[1]})
+	}
+	return FromCommits(commits), nil
+}
+
+// minCancelLength is the length of the shortest commit reference a
+// Cancel-changelog trailer may use, like the default abbreviation of git.
+const minCancelLength = 7
+
+// FromCommits returns the changelog of the given commits, oldest first.
+// Commits which don't follow the conventional commit format are left out.
+func FromCommits(commits []Commit) *Changelog {
+	var cancelled []string
+	parsed := make([]*commitlint.Message, len(commits))
+	for i, c := range commits {
+		msg, err := commitlint.Parse(c.Message)
+		if err != nil {
+			continue
+		}
+		parsed[i] = msg
+		for _, t := range msg.TrailersOf(commitlint.TrailerCancelChangelog) {
+			// Shorter references would cancel unrelated commits.
+			if sha := strings.Fields(t.Value); len(sha) > 0 && len(sha[0]) >= minCancelLength {
+				cancelled = append(cancelled, strings.ToLower(sha[0]))
+			}
+		}
+	}
+
+	cl := &Changelog{}
+	groups := map[string][]Entry{}
+	seen := map[string]bool{}
+	tickets := map[string]bool{}
+	// Only the tickets of the rendered entries are listed.
+	addTickets := func(e Entry) {
+		for _, ticket := range e.Tickets {
+			if !tickets[ticket] {
+				tickets[ticket] = true
+				cl.Tickets = append(cl.Tickets, ticket)
+			}
+		}
+	}
+	for i, c := range commits {
+		msg := parsed[i]
+		if msg == nil || isCancelled(c.SHA, cancelled) || msg.Scope == "internal" {
+			continue
+		}
+		// Deprecations are listed even without a changelog entry.
+		for _, t := range msg.TrailersOf(commitlint.TrailerDeprecation) {
+			cl.Deprecations = append(cl.Deprecations, t.Value)
+		}
+		commitEntries := entries(c.SHA, msg)
+		if commitEntries == nil {
+			continue
+		}
+		for _, e := range commitEntries {
+			if msg.IsBreaking() {
+				addTickets(e)
+				cl.Breaking = append(cl.Breaking, e)
+				continue
+			}
+			name := group(e, msg)
+			// Entries which would render identically, like recurring
+			// dependency bumps, are listed once.
+			key := e.Scope + "|" + e.Line + "|" + strings.Join(e.Tickets, ",")
+			if name == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			addTickets(e)
+			groups[name] = append(groups[name], e)
+		}
+	}
+	for _, name := range groupOrder {
+		if len(groups[name]) > 0 {
+			cl.Groups = append(cl.Groups, Group{Title: name, Entries: groups[name]})
+		}
+	}
+	return cl
+}
+
+func isCancelled(sha string, cancelled []string) bool {
+	for _, prefix := range cancelled {
+		if strings.HasPrefix(sha, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// entries returns the changelog entries of a commit. There is one for every
+// Changelog trailer with a sentence, or else one for the subject.
+func entries(sha string, msg *commitlint.Message) []Entry {
+	base := Entry{
+		Commit: sha,
+		Type:   strings.ToLower(msg.Type),
+		Scope:  msg.Scope,
+		Line:   upperFirst(msg.Subject),
+	}
+	for _, t := range msg.TrailersOf(commitlint.TrailerTicket) {
+		if t.Value != "None" {
+			base.Tickets = append(base.Tickets, t.Value)
+		}
+	}
+	if t, ok := msg.Trailer(commitlint.TrailerBreakingChange); ok {
+		base.Breaking = t.Value
+	}
+
+	var result []Entry
+	withBody := false
+	for _, t := range msg.TrailersOf(commitlint.TrailerChangelog) {
+		// Keywords only count if spelled exactly, like in cliff.toml.
+		switch t.Value {
+		case "None":
+			return nil
+		case "Commit", "All":
+			withBody = true
+			continue
+		case "Title":
+			continue
+		}
+		switch strings.ToLower(t.Value) {
+		case "none", "commit", "all", "title":
+			continue
+		}
+		e := base
+		e.Line = t.Value
+		if t.Type != "" {
+			e.Type = strings.ToLower(t.Type)
+		}
+		result = append(result, e)
+	}
+	if len(result) == 0 {
+		result = append(result, base)
+	}
+	if withBody {
+		result[0].Body = body(msg.Body)
+	}
+	return result
+}
+
+// body removes cherry pick notes from a commit body.
+func body(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if !strings.Contains(line, "(cherry picked from commit") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func ticketLink(ticket string) string {
+	return fmt.Sprintf("[%s](%s%s)", ticket, TicketURL, ticket)
+}
+
+func (e Entry) String() string {
+	var b strings.Builder
+	if e.Scope != "" {
+		fmt.Fprintf(&b, "*(%s)* ", e.Scope)
+	}
+	b.WriteString(e.Line)
+	for _, ticket := range e.Tickets {
+		fmt.Fprintf(&b, " (%s)", ticketLink(ticket))
+	}
+	return b.String()
+}
+
+// WriteMarkdown renders the changelog as Markdown, under a "## <title>"
+// heading, or "## [unreleased]" if the title is empty.
+func (c *Changelog) WriteMarkdown(w io.Writer, title string) error {
+	if title == "" {
+		title = "[unreleased]"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n", title)
+
+	if len(c.Breaking) > 0 {
+		b.WriteString("\n### Breaking changes\n\n")
+		for _, e := range c.Breaking {
+			writeEntry(&b, e)
+			if e.Breaking != "" && e.Breaking != e.Line {
+				fmt.Fprintf(&b, "  - **BREAKING**: %s\n",
+					strings.ReplaceAll(e.Breaking, "\n", "\n    "))
+			}
+		}
+	}
+	if len(c.Deprecations) > 0 {
+		b.WriteString("\n### Deprecations\n\n")
+		for _, d := range c.Deprecations {
+			fmt.Fprintf(&b, "- %s\n", strings.ReplaceAll(d, "\n", "\n  "))
+		}
+	}
+	for _, g := range c.Groups {
+		fmt.Fprintf(&b, "\n### %s\n\n", g.Title)
+		for _, e := range g.Entries {
+			writeEntry(&b, e)
+		}
+	}
+	if len(c.Tickets) > 0 {
+		b.WriteString("\n---\n### All tickets resolved in this release\n\n| Ticket |\n|---|\n")
+		for _, ticket := range c.Tickets {
+			fmt.Fprintf(&b, "| %s |\n", ticketLink(ticket))
+		}
+	}
+	if len(c.Breaking)+len(c.Deprecations)+len(c.Groups) == 0 {
+		b.WriteString("\nNo Changelog found.\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeEntry(b *strings.Builder, e Entry) {
+	fmt.Fprintf(b, "- %s\n", e)
+	if e.Body == "" {
+		return
+	}
+	for _, line := range strings.Split(e.Body, "\n") {
+		if line == "" {
+			b.WriteString("\n")
+		} else {
+			fmt.Fprintf(b, "  %s\n", line)
+		}
+	}
+}
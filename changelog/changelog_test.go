@@ -0,0 +1,142 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package changelog
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func markdown(t *testing.T, cl *Changelog, title string) string {
+	var b strings.Builder
+	require.NoError(t, cl.WriteMarkdown(&b, title))
+	return b.String()
+}
+
+func TestFromCommits(t *testing.T) {
+	cl := FromCommits([]Commit{
+		{"a1", "feat(api)!: remove legacy endpoint\n\n" +
+			"BREAKING CHANGE: Clients must migrate to /v2.\n\nTicket: MEN-1\n"},
+		{"a2", "feat(deployments): trim logs\n\nChangelog: Devices report log tails.\n" +
+			"Ticket: MEN-2\n"},
+		{"a3", "fix(client): prevent crash\n\nChangelog: None\nTicket: MEN-3\n"},
+		{"a4", "fix: handle EOF\n\nThe reader stopped early.\n\nChangelog: Commit\n" +
+			"Ticket: None\n(cherry picked from commit 0123abcd)\n"},
+		{"a5", "refactor: rename filter\n"},
+		{"a6", "chore: tidy up\n\nChangelog(fix): Fixed a leak in the tidy up code.\n"},
+		{"a7", "chore: bump golang.org/x/net\n"},
+		{"a8", "chore: bump golang.org/x/net\n"},
+		{"a9", "chore: bump golang.org/x/crypto\n\nFixes CVE-2024-1234.\n"},
+		{"b1", "docs: explain things\n\nTicket: MEN-4\n"},
+		{"b2", "chore: announce\n\nDeprecation: The v1 API will be removed.\n" +
+			"Changelog: None\n"},
+		{"b3c0ffee0", "feat: reverted later\n"},
+		{"b4", "chore: cancel\n\nCancel-changelog: B3C0FFE\n"},
+		{"b7", "chore: cancel too much\n\nCancel-changelog: a\n"},
+		{"b5", "fix(internal): hidden\n"},
+		{"b6", "not conventional\n"},
+	})
+
+	assert.Equal(t, `## 1.0.0
+
+### Breaking changes
+
+- *(api)* Remove legacy endpoint ([MEN-1](https://northerntech.atlassian.net/browse/MEN-1))
+  - **BREAKING**: Clients must migrate to /v2.
+
+### Deprecations
+
+- The v1 API will be removed.
+
+### Security
+
+- Bump golang.org/x/crypto
+
+### New features
+
+- *(deployments)* Devices report log tails. ([MEN-2](https://northerntech.atlassian.net/browse/MEN-2))
+
+### Improvements
+
+- Rename filter
+
+### Bug fixes
+
+- Handle EOF
+  The reader stopped early.
+- Fixed a leak in the tidy up code.
+
+### Dependency updates
+
+- Bump golang.org/x/net
+
+---
+### All tickets resolved in this release
+
+| Ticket |
+|---|
+| [MEN-1](https://northerntech.atlassian.net/browse/MEN-1) |
+| [MEN-2](https://northerntech.atlassian.net/browse/MEN-2) |
+`, markdown(t, cl, "1.0.0"))
+
+	assert.Equal(t, "## [unreleased]\n\nNo Changelog found.\n",
+		markdown(t, FromCommits(nil), ""))
+}
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test Testison",
+			"GIT_AUTHOR_EMAIL=test@test.com",
+			"GIT_COMMITTER_NAME=Test Testison",
+			"GIT_COMMITTER_EMAIL=test@test.com",
+		)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+		return strings.TrimSpace(string(output))
+	}
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "feat: initial")
+	base := git("rev-parse", "HEAD")
+	git("commit", "-q", "--allow-empty", "-m", "fix: first fix\n\nBody text.")
+	git("commit", "-q", "--allow-empty", "-m", "feat: second feature")
+	git("checkout", "-q", "-b", "side")
+	git("commit", "-q", "--allow-empty", "-m", "fix: on a branch")
+	git("checkout", "-q", "-")
+	git("merge", "-q", "--no-ff", "-m", "Merge branch side", "side")
+
+	cl, err := Generate(context.Background(), dir, base+"..HEAD")
+	require.NoError(t, err)
+	require.Len(t, cl.Groups, 2)
+	assert.Equal(t, GroupFeatures, cl.Groups[0].Title)
+	assert.Equal(t, "Second feature", cl.Groups[0].Entries[0].Line)
+	assert.Equal(t, GroupFixes, cl.Groups[1].Title)
+	require.Len(t, cl.Groups[1].Entries, 2)
+	assert.Equal(t, "First fix", cl.Groups[1].Entries[0].Line)
+	assert.Empty(t, cl.Groups[1].Entries[0].Body)
+	assert.Equal(t, "On a branch", cl.Groups[1].Entries[1].Line)
+
+	_, err = Generate(context.Background(), dir, "nonexistent..HEAD")
+	assert.Error(t, err)
+}
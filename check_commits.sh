@@ -19,12 +19,15 @@ if [ -n "$DEBUG_MENDERTESTING" ]; then
     set -x
 fi
 
+. "$(dirname "$(realpath "${BASH_SOURCE[0]}")")/mendertesting_config.sh"
+
 while [[ $# -gt 0 ]]
 do
     case "$1" in
         -h|--help)
             echo "usage: $(basename $0) [OPTIONS] <git-range>"
             echo
+            echo "    -C <dir>      Check the repository in <dir>"
             echo "    --signoffs    Enable checking of signoffs"
             echo "    --changelogs  Enable checking of changelogs"
             echo "    --schema      Enable checking of the commit schema format (conventional commits required)"
@@ -34,8 +37,17 @@ do
             echo
             echo "NOTE: In the case that none of the above flags are set"
             echo "      then they are all enabled by default."
+            echo
+            echo "If the mendertesting command is in PATH, or MENDERTESTING is set to"
+            echo "its path, it is used to lint the commit messages against grammar.md,"
+            echo "or the strict legacy rules if COMMITLINT_LEGACY is set."
             exit 1
             ;;
+        -C)
+            cd "$2"
+            shift 2
+            continue
+            ;;
         -s|--signoffs)
             CHECK_SIGNOFFS=TRUE
             shift
@@ -57,7 +69,15 @@ do
     esac
 done
 
+read_mendertesting_config
+
+if ! check_enabled commits; then
+    echo "The commits check is not enabled in .mendertesting.yaml"
+    exit 0
+fi
+
 function check_required_tools() {
+    [ -n "$MENDERTESTING" ] && return
     which awk >/dev/null || { echo >&2 "AWK is a required tool for the commit check. Please install it"; exit 1; }
 }
 
@@ -159,9 +179,20 @@ function branch_exists_in_remote() {
     git remote show origin 2>/dev/null | grep -q -E "\b$1\b"
 }
 
+# The mendertesting command reports every problem in a commit message, the gawk
+# commitlint only the first one, and knows the commit types and scopes of
+# .mendertesting.yaml.
+function commitlint() {
+    if [ -n "$MENDERTESTING" ]; then
+        "$MENDERTESTING" commitlint ${COMMITLINT_LEGACY:+--legacy}
+    else
+        $(dirname $(realpath ${BASH_SOURCE[0]}))/commitlint/commitlint
+    fi
+}
+
 function check_conventional_commits() {
     local -r git_msg="$(git show -s --format=%B $1)"
-    if ! echo "${git_msg}" | $(dirname $(realpath ${BASH_SOURCE[0]}))/commitlint/commitlint; then
+    if ! echo "${git_msg}" | commitlint; then
         echo >&2 "Commit $1 does not adhere to the conventional commit specification, used in the Mender project"
         echo >&2 "See https://github.com/mendersoftware/mendertesting/blob/master/commitlint/grammar.md for more information"
         notvalid="$notvalid $1"
@@ -19,8 +19,61 @@ if [ -n "$DEBUG_MENDERTESTING" ]; then
     set -x
 fi
 
+. "$(dirname "$(realpath "${BASH_SOURCE[0]}")")/mendertesting_config.sh"
+
 ret=0
 
+CHKSUM_FILE=LIC_FILES_CHKSUM.sha256
+
+usage() {
+    echo "Usage: $(basename "$0") [-C <dir>] [--add-license=<file>]... [<dir-to-check>]"
+}
+
+while [ -n "$1" ]; do
+    case "$1" in
+        -C)
+            if [ -z "$2" ]; then
+                usage
+                exit 1
+            fi
+            REPO_ROOT="$2"
+            shift
+            ;;
+        --add-license=*)
+            ADDED_LICENSE_FILES="$ADDED_LICENSE_FILES ${1#--add-license=}"
+            ;;
+        -*)
+            usage
+            exit 1
+            ;;
+        *)
+            REPO_ROOT="$1"
+            ;;
+    esac
+    shift
+done
+
+if [ -n "$REPO_ROOT" ]; then
+    cd "$REPO_ROOT"
+fi
+
+read_mendertesting_config
+
+KNOWN_LICENSE_FILES="$KNOWN_LICENSE_FILES$ADDED_LICENSE_FILES"
+
+if ! check_enabled license-year && ! check_enabled licenses; then
+    echo "The license checks are not enabled in .mendertesting.yaml"
+    exit 0
+fi
+
+# Every known license file must exist in LIC_FILES_CHKSUM.sha256.
+for file in $KNOWN_LICENSE_FILES; do
+    if ! grep -F -q "$file" $CHKSUM_FILE; then
+        echo "$file does not have a checksum in $CHKSUM_FILE"
+        exit 1
+    fi
+done
+
 ################################################################################
 # Check main license file.
 ################################################################################
@@ -29,38 +82,39 @@ ret=0
 LATEST="$(git log --no-merges --format="%at %H" | sort -rn | head -n1 | cut -d' ' -f2)"
 LATEST_YEAR="$(git log -n1 --format=%ad --date=format:%Y $LATEST)"
 
-if ! grep -siq "Copyright *$LATEST_YEAR *Northern.tech" LICENSE && ! grep -siq "Copyright *$LATEST_YEAR *Northern.tech" LICENSE.md; then
-    echo "'Copyright $LATEST_YEAR Northern.tech' not found in LICENSE. Wrong year maybe?"
+HOLDER="${COPYRIGHT_HOLDER:-Northern.tech}"
+# The holder is matched literally, like licenses.Checker.CheckTopLevel does.
+HOLDER_REGEXP="$(printf '%s' "$HOLDER" | sed 's/[][\.*^$]/\\&/g')"
+
+if check_enabled license-year \
+        && ! grep -siq "Copyright *$LATEST_YEAR *$HOLDER_REGEXP" LICENSE \
+        && ! grep -siq "Copyright *$LATEST_YEAR *$HOLDER_REGEXP" LICENSE.md; then
+    echo "'Copyright $LATEST_YEAR $HOLDER' not found in LICENSE. Wrong year maybe?"
     ret=1
 fi
 
+if ! check_enabled licenses; then
+    exit ${ret}
+fi
+
 ################################################################################
 # Check license of dependencies.
 ################################################################################
 
-CHKSUM_FILE=LIC_FILES_CHKSUM.sha256
-
-while [ -n "$1" ]; do
-    case "$1" in
-        --add-license=*)
-            file="${1#--add-license=}"
-            KNOWN_LICENSE_FILES="$KNOWN_LICENSE_FILES $file"
-            # The file must exist in LIC_FILES_CHKSUM.sha256
-            if ! grep -F -q "$file" $CHKSUM_FILE; then
-                echo "$file does not have a checksum in $CHKSUM_FILE"
-                exit 1
-            fi
-            ;;
-        -*)
-            echo "Usage: $(basename "$0") <dir-to-check>"
-            exit 1
-            ;;
-    esac
-    shift
-done
+# There must be a license at the top level.
+if [ LICENSE* = "LICENSE*" ] && [ COPYING* = "COPYING*" ]; then
+    echo "No top level license file."
+    ret=1
+fi
 
-if [ -n "$1" ]; then
-    cd "$1"
+# The mendertesting command checks the checksums itself, so shasum isn't
+# needed, and reports every problem: unlisted and changed license files,
+# uncovered vendored packages, license files listed under the group of
+# another license, and licenses denied in .mendertesting.yaml.
+if [ -n "$MENDERTESTING" ]; then
+    "$MENDERTESTING" licenses check \
+        ${KNOWN_LICENSE_FILES:+--add-license="$KNOWN_LICENSE_FILES"} || ret=1
+    exit ${ret}
 fi
 
 # Remove all newlines from the Checksum file as these are reported as formatting
@@ -95,14 +149,12 @@ while read -r file; do
     # combined license listing.
     if ! grep -F "$(shasum -a 256 $file)" $TMP_CHKSUM_FILE > /dev/null && ! grep "^$file\$" .COVERED_LICENSES >&/dev/null; then
         echo >&2 "$file has missing or wrong entry in $CHKSUM_FILE"
+        unlisted=1
         ret=1
     fi
 done < <(find . \( -type f -iname 'LICEN[SC]E' -o -iname 'LICEN[SC]E.*' -o -iname 'COPYING' \) -and -not -iname '*.go' -and -not -iname '*.c' -and -not -iname '*.cpp')
-
-# There must be a license at the top level.
-if [ LICENSE* = "LICENSE*" ] && [ COPYING* = "COPYING*" ]; then
-    echo "No top level license file."
-    ret=1
+if [ -n "$unlisted" ]; then
+    echo >&2 "Run \"mendertesting licenses update\" to update $CHKSUM_FILE."
 fi
 
 # There must be a license at the top level of each Go dependency.
@@ -17,6 +17,8 @@ if [ -n "$DEBUG_MENDERTESTING" ]; then
     set -x
 fi
 
+. "$(dirname "$(realpath "${BASH_SOURCE[0]}")")/mendertesting_config.sh"
+
 # This regular expression can be set in the '.gitlab-ci.yml' file, and is passed
 # on to the find expression used to aggregate all the files to check for license
 # headers.
@@ -24,14 +26,26 @@ LICENSE_HEADERS_IGNORE_FILES_REGEXP="${LICENSE_HEADERS_IGNORE_FILES_REGEXP:-none
 
 usage() {
     cat <<EOF
-$(basename "$0") [--ent-start-commit=COMMIT]
+$(basename "$0") [-C DIR] [--ent-start-commit=COMMIT] [--changed-since=REV]
 
 Checks that all licenses in Go and Python files are correct.
 
+Files matching the patterns in the .licenseignore file at the top of the
+repository are not checked. It uses the same format as .gitignore.
+
+-C DIR
+	Check the repository in DIR instead of the current directory.
+
 --ent-start-commit=COMMIT
 	For an Enterprise repository, specifies the earliest commit that is part
 	of only Enterprise (the very first commit after the fork point).
 
+--changed-since=REV
+	Only check the files added, renamed or modified since REV, including
+	uncommitted and untracked ones. All files are checked if .licenseignore,
+	.mendertesting.yaml or a header template changed since REV, or if the
+	Enterprise start commit isn't an ancestor of REV.
+
 If the FIRST_ENT_COMMIT env variable is set, the script uses its value for
 the --ent-start-commit parameter.
 
@@ -43,7 +57,36 @@ LICENSE_HEADERS_IGNORE_FILES_REGEXP:
 
     $(find . -type f \( ! -regex ${LICENSE_HEADERS_IGNORE_FILES_REGEXP} ...
 
-  and can therefore be used to ignore files in a repository.
+  and can therefore be used to ignore files in a repository, in addition to
+  .licenseignore.
+
+LICENSE_HEADERS_FILE_TYPES:
+
+  Comma separated file types to check besides Go, C, C++, Python and Shell,
+  like "ruby,yaml,dockerfile". See "mendertesting headers -h" for all types.
+  This requires the mendertesting command.
+
+LICENSE_HEADERS_GENERATED:
+
+  How files marked as generated, with a "Code generated ... DO NOT EDIT." or
+  "@generated" comment before the code starts, are checked: "skip", the
+  default, or "check".
+
+LICENSE_HEADERS_STYLES:
+
+  Comma separated accepted header styles: "full" for the full license text,
+  and "spdx" for an SPDX-License-Identifier line. Defaults to "full". This
+  requires the mendertesting command.
+
+COPYRIGHT_HOLDER:
+
+  The holder in the copyright lines, instead of "Northern.tech AS".
+
+MENDERTESTING:
+
+  The path of the mendertesting command. If it is set, or in PATH, the check
+  is done by "mendertesting headers", which also reads the header templates
+  declared in .mendertesting.yaml.
 EOF
 }
 
@@ -51,6 +94,10 @@ ENT_COMMIT="${FIRST_ENT_COMMIT}"
 
 while [ -n "$1" ]; do
     case "$1" in
+        -C)
+            shift
+            cd "$1"
+            ;;
         --ent-start-commit=*)
             ENT_COMMIT="${1#--ent-start-commit=}"
             ;;
@@ -58,6 +105,13 @@ while [ -n "$1" ]; do
             shift
             ENT_COMMIT="$1"
             ;;
+        --changed-since=*)
+            CHANGED_SINCE="${1#--changed-since=}"
+            ;;
+        --changed-since)
+            shift
+            CHANGED_SINCE="$1"
+            ;;
         --verbose)
             set -x
             ;;
@@ -70,50 +124,84 @@ while [ -n "$1" ]; do
     shift
 done
 
-is_enterprise() {
-    local file="$1"
+read_mendertesting_config
+
+ENT_COMMIT="${ENT_COMMIT:-$FIRST_ENT_COMMIT}"
+
+if ! check_enabled headers; then
+    echo "The headers check is not enabled in .mendertesting.yaml"
+    exit 0
+fi
+
+if [ -n "$MENDERTESTING" ]; then
+    exec "$MENDERTESTING" headers ${ENT_COMMIT:+--ent-start-commit="$ENT_COMMIT"} \
+         ${CHANGED_SINCE:+--changed-since="$CHANGED_SINCE"}
+elif [ -n "$LICENSE_HEADERS_FILE_TYPES" ]; then
+    echo >&2 "LICENSE_HEADERS_FILE_TYPES requires the mendertesting command"
+    exit 1
+elif [ -n "$LICENSE_HEADERS_STYLES" ]; then
+    echo >&2 "LICENSE_HEADERS_STYLES requires the mendertesting command"
+    exit 1
+fi
+
+# The git history is read once: the latest year in which every file was added,
+# following renames, and the files of the latest Open Source commit.
+declare -A ADDED_YEARS
+declare -A OPEN_SOURCE_FILES
+
+read_history() {
+    local file year
+    if git rev-parse --verify --quiet HEAD > /dev/null; then
+        while IFS=$'\t' read -r file year; do
+            ADDED_YEARS["$file"]="$year"
+        done < <(git -c core.quotePath=false log --reverse --topo-order --format=%ad \
+                     --date=format:%Y --name-status -M -c --relative HEAD \
+                     | awk -F '\t' '
+                         function latest(f, y) { if (!(f in added) || y > added[f]) added[f] = y }
+                         NF == 1 { year = $1; next }
+                         $1 ~ /^A+$/ { latest($2, year) }
+                         $1 ~ /^R/ && ($2 in added) { latest($3, added[$2]) }
+                         END { for (f in added) print "./" f "\t" added[f] }')
+    fi
 
     if [ -z "$ENT_COMMIT" ]; then
         # If there is no Enterprise commit specified, then this isn't an
         # Enterprise repository, so everything is Open Source.
-        return 1
+        return
     fi
 
     # Find the latest commit that is not a descendant of the Enterprise
-    # commit. This should be the latest Open Source commit. This doesn't change
-    # over the course of a run, so cache it.
+    # commit. This should be the latest Open Source commit.
+    LATEST_OS_COMMIT=$(git rev-list $ENT_COMMIT..HEAD --ancestry-path --boundary --date-order \
+                           | grep -v $ENT_COMMIT \
+                           | grep "^-" \
+                           | head -n1 \
+                           | sed -e 's/[^0-9a-f]//')
     if [ -z "$LATEST_OS_COMMIT" ]; then
-        LATEST_OS_COMMIT=$(git rev-list $ENT_COMMIT..HEAD --ancestry-path --boundary --date-order \
-                               | grep -v $ENT_COMMIT \
-                               | grep "^-" \
-                               | head -n1 \
-                               | sed -e 's/[^0-9a-f]//')
-        if [ -z "$LATEST_OS_COMMIT" ]; then
-            # Very unlikely, but this can happen if every descendant commit of
-            # ENT_COMMIT has no other ancestor. This can only happen if:
-            #
-            # 1) Open Source has never been merged into the repo after the fork.
-            #
-            # 2) ENT_COMMIT was pushed directly to the repo, instead of being
-            #    merged.
-            #
-            # If so, the correct commit to set is $ENT_COMMIT~1
-            LATEST_OS_COMMIT=$ENT_COMMIT~1
-        fi
+        # Very unlikely, but this can happen if every descendant commit of
+        # ENT_COMMIT has no other ancestor. This can only happen if:
+        #
+        # 1) Open Source has never been merged into the repo after the fork.
+        #
+        # 2) ENT_COMMIT was pushed directly to the repo, instead of being
+        #    merged.
+        #
+        # If so, the correct commit to set is $ENT_COMMIT~1
+        LATEST_OS_COMMIT=$ENT_COMMIT~1
     fi
-
-    # There is no commit before ENT_COMMIT, all code is enterprise
-    if [ "$LATEST_OS_COMMIT" = "$ENT_COMMIT" ]; then
-        return 0
+    # There is no commit before ENT_COMMIT, all code is enterprise.
+    if git rev-parse --verify --quiet "$LATEST_OS_COMMIT" > /dev/null; then
+        while IFS= read -r file; do
+            OPEN_SOURCE_FILES["./$file"]=1
+        done < <(git -c core.quotePath=false ls-tree -r --name-only "$LATEST_OS_COMMIT")
     fi
+}
 
-    if git show $LATEST_OS_COMMIT:"$file" >& /dev/null; then
-        # File exists, it's Open Source.
-        return 1
-    else
-        # File does not exist, it's Enterprise.
-        return 0
-    fi
+is_enterprise() {
+    local file="$1"
+    # Files which don't exist in the latest Open Source commit are
+    # Enterprise.
+    [ -n "$ENT_COMMIT" ] && [ -z "${OPEN_SOURCE_FILES["$file"]}" ]
 }
 
 TEST_RESULT=0
@@ -122,10 +210,16 @@ strip_hashbang() {
     if [[ $#  -ne 1 ]]; then
         echo >&2 "strip_hashbang: Missing argument"
     fi
-    tf=$(mktemp)
     local -r file="${1}"
-    awk '!(NR == 1 && $1 ~/^#!.*/)' "${file}" > "${tf}"
-    echo "${tf}"
+    # Besides the "#!" line, Python encoding cookies and Go build constraints
+    # must stay in front of the license header.
+    awk -v ext="${file##*.}" '
+        body { print; next }
+        NR == 1 && $1 ~ /^#!/ { next }
+        ext == "py" && NR <= 2 && !cookie && /^[ \t\f]*#.*coding[:=][ \t]*[-_.a-zA-Z0-9]+/ { cookie = 1; next }
+        ext == "go" && /^\/\/(go:build|[ \t]*\+build)[ \t]/ { constraints = 1; next }
+        constraints && /^[ \t]*$/ { next }
+        { body = 1; print }' "${file}"
 }
 
 check_file() {
@@ -176,15 +270,24 @@ EOF
         lic_type="Open Source"
     fi
 
-    added_year=$(git log --follow --format=%ad --date=format:%Y --diff-filter=A -- "$file" | sort -n | tail -n 1)
+    # Files that aren't committed yet are about to be added.
+    added_year="${ADDED_YEARS["$file"]:-$(date +%Y)}"
 
     orig_file="${file}"
-    file=$(strip_hashbang "${file}")
+    strip_hashbang "${file}" > "${STRIPPED_FILE}"
+    file="${STRIPPED_FILE}"
+
+    # The header may start with several copyright lines, like one of an
+    # original author. Without any, the first line is taken as one.
+    copyright_lines=$(awk -v cm="${CM} Copyright " \
+        'index($0, cm) == 1 { n++; next } { exit } END { print (n > 0 ? n : 1) }' "$file")
+    lines=$(($lines + $copyright_lines - 1))
 
-    head -n $lines "$file" | tail -n +3 | diff -u "$license" - > /dev/null
+    head -n $lines "$file" | tail -n +$(($copyright_lines + 2)) | diff -u "$license" - > /dev/null
     rc=$?
     if [[ $rc -ne 0 ]]; then
-        head -n $lines "$file" | sed -e "s/${tab}/    /g" | tail -n +3 | diff -u "$license" - > /dev/null
+        head -n $lines "$file" | sed -e "s/${tab}/    /g" | tail -n +$(($copyright_lines + 2)) \
+            | diff -u "$license" - > /dev/null
         rc=$?
     fi
     if [ $rc -ne 0 ]; then
@@ -192,8 +295,10 @@ EOF
         cat "$license" >&2
         TEST_RESULT=1
     else
-        copyright_year=$(grep -oP "${CM} Copyright \d{4} Northern.tech AS" "$file" | grep -oP "\d{4}")
-        if [ $copyright_year -ge $added_year ]; then
+        # The latest year of a range or list, like "2019-2024", counts.
+        copyright_year=$(grep -oP "^\Q${CM}\E Copyright \K[0-9][0-9, -]*(?= \Q${COPYRIGHT_HOLDER:-Northern.tech AS}\E)" "$file" \
+            | head -n1 | grep -oP "\d{4}" | sort -n | tail -n1)
+        if [ "${copyright_year:-0}" -ge "$added_year" ]; then
             # Success!
             :
         else
@@ -204,12 +309,36 @@ EOF
     return ${TEST_RESULT}
 }
 
+# Generated files carry a "Code generated ... DO NOT EDIT." or "@generated"
+# comment.
+GENERATED_REGEXP='^[[:space:]]*(//|#|--|/?\*)[[:space:]]*(Code generated .* DO NOT EDIT\b|@generated\b)'
+
+# leading_comments FILE prints the lines before the first line of code, where
+# the generated file comment must be.
+leading_comments() {
+    awk '
+        block { print; if (index($0, "*/")) block = 0; next }
+        /^[[:space:]]*$/ || /^[[:space:]]*(\/\/|#|--)/ { print; next }
+        /^[[:space:]]*\/\*/ { print; if (!index(substr($0, index($0, "/*") + 2), "*/")) block = 1; next }
+        { exit }' "$1"
+}
+
 check_files() {
     if [[ ! $# -ge 1 ]]; then
         echo >&2 "check_files requires one or more parameters"
     fi
     local -r SOURCE_FILES="$@"
     for source_file in ${SOURCE_FILES}; do
+        if grep -qxF "${source_file}" "${LICENSE_IGNORED_FILES}"; then
+            continue
+        fi
+        if [ -n "${CHANGED_FILES}" ] && ! grep -qxF "${source_file}" "${CHANGED_FILES}"; then
+            continue
+        fi
+        if [ "${LICENSE_HEADERS_GENERATED:-skip}" = skip ] \
+                && leading_comments "${source_file}" | grep -qE "${GENERATED_REGEXP}"; then
+            continue
+        fi
         case ${source_file} in
           *.go|*.[ch]|*.[ch]pp)
               CM='//'
@@ -231,6 +360,47 @@ check_files() {
 
 echo >&2 "LICENSE_HEADERS_IGNORE_FILES_REGEXP: ${LICENSE_HEADERS_IGNORE_FILES_REGEXP}"
 
+# git applies the patterns in .licenseignore just like a .gitignore file.
+LICENSE_IGNORED_FILES=$(mktemp)
+STRIPPED_FILE=$(mktemp)
+trap 'rm -f "${LICENSE_IGNORED_FILES}" "${STRIPPED_FILE}"' EXIT
+if [ -f .licenseignore ]; then
+    git ls-files --cached --others --ignored --exclude-from=.licenseignore \
+        | sed -e 's,^,./,' > "${LICENSE_IGNORED_FILES}"
+fi
+
+# policy_files lists the files that make up the license header policy:
+# .licenseignore, .mendertesting.yaml and the header templates it refers to,
+# which are its only scalar open_source and enterprise values.
+policy_files() {
+    echo ./.licenseignore
+    echo ./.mendertesting.yaml
+    if [ -f .mendertesting.yaml ]; then
+        sed -nE "s/^[[:space:]-]*(open_source|enterprise):[[:space:]]*[\"']?([^\"'#[:space:]]+).*/\2/p" \
+            .mendertesting.yaml | sed -e 's,^\./,,' -e 's,^,./,'
+    fi
+}
+
+# With --changed-since, only the changed files are checked, unless the policy
+# or the Enterprise fork point changed.
+CHANGED_FILES=
+if [ -n "$CHANGED_SINCE" ]; then
+    CHANGED_SINCE=$(git rev-parse --verify "${CHANGED_SINCE}^{commit}")
+    CHANGED_FILES=$(mktemp)
+    trap 'rm -f "${LICENSE_IGNORED_FILES}" "${STRIPPED_FILE}" "${CHANGED_FILES}"' EXIT
+    { git diff --name-only --relative --no-renames "$CHANGED_SINCE" --
+      git ls-files --others --exclude-standard; } | sed -e 's,^,./,' > "${CHANGED_FILES}"
+    if policy_files | grep -qxFf - "${CHANGED_FILES}" \
+            || { [ -n "$ENT_COMMIT" ] \
+                     && ! git merge-base --is-ancestor "$ENT_COMMIT" "$CHANGED_SINCE"; }; then
+        echo >&2 "The license policy changed since ${CHANGED_SINCE}, checking all files"
+        rm -f "${CHANGED_FILES}"
+        CHANGED_FILES=
+    fi
+fi
+
+read_history
+
 echo >&2 "Checking licenses on all Go files"
 GO_FILES="\
 $(find . -type f ! -regex "${LICENSE_HEADERS_IGNORE_FILES_REGEXP}" ! -path '*/vendor/*' -name '*.go')
@@ -252,4 +422,8 @@ C_FILES="\
 $(find . -type f ! -regex "${LICENSE_HEADERS_IGNORE_FILES_REGEXP}" ! -path '*/vendor/*' \( -name '*.[ch]' -o -name '*.[ch]pp' \))"
 check_files "${C_FILES}"
 
+if [ ${TEST_RESULT} -ne 0 ]; then
+    echo >&2 "Run \"mendertesting headers --fix\" to fix the headers."
+fi
+
 exit ${TEST_RESULT}
@@ -0,0 +1,56 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mendersoftware/mendertesting/changelog"
+)
+
+// runChangelog prints the Markdown changelog of a git range.
+func runChangelog(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("changelog", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dir := flags.String("C", "", "run as if started in this directory")
+	title := flags.String("title", "", `the heading of the changelog (default "[unreleased]")`)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: mendertesting changelog [-C DIR] [--title TITLE] "+
+			"<git-range>...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	cl, err := changelog.Generate(context.Background(), *dir, strings.Join(flags.Args(), " "))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if err := cl.WriteMarkdown(stdout, *title); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
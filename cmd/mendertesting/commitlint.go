@@ -0,0 +1,76 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mendersoftware/mendertesting/commitlint"
+	"github.com/mendersoftware/mendertesting/config"
+)
+
+// runCommitlint lints the commit message in the given file, or on stdin, and
+// prints every diagnostic to stderr. The commit types and scopes come from the
+// configuration of the repository.
+func runCommitlint(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("commitlint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dir := flags.String("C", ".", "read the configuration of the repository in `dir`")
+	legacy := flags.Bool("legacy", false,
+		"use the strict rules of the gawk commitlint instead of grammar.md")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: mendertesting commitlint [-C dir] [--legacy] [FILE]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var message []byte
+	var err error
+	switch flags.NArg() {
+	case 0:
+		message, err = io.ReadAll(stdin)
+	case 1:
+		message, err = os.ReadFile(flags.Arg(0))
+	default:
+		flags.Usage()
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	cfg, err := config.Load(*dir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	if *legacy {
+		cfg.Commits.Legacy = true
+	}
+	diagnostics := commitlint.Lint(string(message), cfg.CommitlintPolicy())
+	for _, d := range diagnostics {
+		fmt.Fprintf(stderr, "Error: %s\n", d)
+	}
+	if len(diagnostics) > 0 {
+		return 1
+	}
+	return 0
+}
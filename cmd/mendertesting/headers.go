@@ -0,0 +1,118 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mendersoftware/mendertesting/config"
+	"github.com/mendersoftware/mendertesting/headers"
+)
+
+// runHeaders checks the license headers of the source files, like
+// check_license_source_files.sh, or fixes them. The flags override the
+// configuration of the repository.
+func runHeaders(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("headers", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dir := flags.String("C", ".", "check the repository in `dir`")
+	entCommit := flags.String("ent-start-commit", "",
+		"the first Enterprise only `commit`, for Enterprise repositories")
+	types := flags.String("types", "",
+		"comma separated file `types` to check besides the default ones, of: "+
+			strings.Join(headers.FileTypes(), ", "))
+	styles := flags.String("style", "",
+		"comma separated accepted header `styles`, full or spdx; --fix converts other"+
+			" headers to the first one")
+	changedSince := flags.String("changed-since", "",
+		"only check the files added, renamed or modified since `rev`, unless the"+
+			" configuration or the Enterprise fork point changed")
+	fix := flags.Bool("fix", false, "fix missing and outdated headers")
+	dryRun := flags.Bool("dry-run", false,
+		"print the fixes as a diff instead of writing them, implies --fix")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr,
+			"usage: mendertesting headers [-C dir] [--ent-start-commit=COMMIT] [--types=TYPES]"+
+				" [--style=STYLES] [--changed-since=REV] [--fix [--dry-run]]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	cfg, err := config.Load(*dir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	if *entCommit != "" {
+		cfg.FirstEnterpriseCommit = *entCommit
+	}
+	if *types != "" {
+		cfg.Headers.FileTypes = headers.SplitTypes(*types)
+	}
+	if *styles != "" {
+		cfg.Headers.Styles = headers.SplitTypes(*styles)
+	}
+	checker, err := cfg.HeadersChecker(*dir)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	checker.ChangedSince = *changedSince
+
+	ctx := context.Background()
+	if !*fix && !*dryRun {
+		findings, err := checker.Check(ctx)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		for _, finding := range findings {
+			fmt.Fprintf(stderr, "!!! FAILED license check on %s\n", finding)
+		}
+		if len(findings) > 0 {
+			fmt.Fprintln(stderr, "Run \"mendertesting headers --fix\" to fix the headers.")
+			return 1
+		}
+		return 0
+	}
+
+	changes, err := checker.Fix(ctx, *dryRun)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	for _, change := range changes {
+		if *dryRun {
+			fmt.Fprint(stdout, change.Diff())
+		} else {
+			fmt.Fprintf(stdout, "Fixed %s\n", change.Finding.Path)
+		}
+	}
+	// Like a check, a dry run fails if anything needs to be fixed.
+	if *dryRun && len(changes) > 0 {
+		return 1
+	}
+	return 0
+}
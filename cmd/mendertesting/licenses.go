@@ -0,0 +1,310 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mendersoftware/mendertesting/config"
+	"github.com/mendersoftware/mendertesting/licenses"
+)
+
+var licensesCommands = map[string]command{
+	"check":    runLicensesCheck,
+	"identify": runLicensesIdentify,
+	"update":   runLicensesUpdate,
+	"vendor":   runLicensesVendor,
+}
+
+// runLicenses runs a subcommand about the licenses of the dependencies.
+func runLicenses(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		licensesUsage(stderr)
+		return 2
+	}
+	cmd, ok := licensesCommands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown licenses command %q\n", args[0])
+		licensesUsage(stderr)
+		return 2
+	}
+	return cmd(args[1:], stdin, stdout, stderr)
+}
+
+func licensesUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: mendertesting licenses <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	var names []string
+	for name := range licensesCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "    %s\n", name)
+	}
+}
+
+// repoFlags are the flags of the licenses commands which select the
+// repository and its license files.
+type repoFlags struct {
+	dir   *string
+	added []string
+}
+
+// addRepoFlags adds -C and --add-license to the flags. The verb describes
+// what the command does to the repository.
+func addRepoFlags(flags *flag.FlagSet, verb string) *repoFlags {
+	repo := &repoFlags{}
+	repo.dir = flags.String("C", ".", verb+" the repository in `dir`")
+	flags.Func("add-license", "a license `file` of a dependency with an uncommon name,"+
+		" may be repeated", func(value string) error {
+		repo.added = append(repo.added, strings.Fields(value)...)
+		return nil
+	})
+	return repo
+}
+
+// checker returns the license checker of the repository, with the policy of
+// its configuration and the added license files.
+func (r *repoFlags) checker() (*licenses.Checker, error) {
+	cfg, err := config.Load(*r.dir)
+	if err != nil {
+		return nil, err
+	}
+	return &licenses.Checker{
+		Root:              *r.dir,
+		KnownLicenseFiles: append(cfg.Licenses.KnownLicenseFiles, r.added...),
+		Policy:            cfg.LicensePolicy(),
+	}, nil
+}
+
+// runLicensesCheck checks the licenses of the dependencies like the
+// dependency part of check_license.sh, without shasum: the checksum file,
+// the vendor coverage, the license groups and the policy.
+func runLicensesCheck(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("licenses check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	repo := addRepoFlags(flags, "check")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: mendertesting licenses check [-C dir] [--add-license=FILE]...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	checker, err := repo.checker()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	findings, err := checker.CheckDependencies()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	outdated := false
+	for _, f := range findings {
+		fmt.Fprintln(stderr, f)
+		outdated = outdated || f.Rule == licenses.RuleUnlisted ||
+			f.Rule == licenses.RuleChecksumMismatch
+	}
+	if outdated {
+		fmt.Fprintf(stderr, "Run \"mendertesting licenses update\" to update %s.\n",
+			licenses.ChecksumFileName)
+	}
+	if len(findings) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runLicensesVendor prints the license file covering every vendored Go
+// package, and fails if any package is not covered, like the vendor part of
+// check_license.sh.
+func runLicensesVendor(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("licenses vendor", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	repo := addRepoFlags(flags, "check")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: mendertesting licenses vendor [-C dir] [--add-license=FILE]...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	checker, err := repo.checker()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	packages, err := licenses.VendorPackages(checker.Root, checker.KnownLicenseFiles)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	code := 0
+	for _, pkg := range packages {
+		if pkg.License == "" {
+			fmt.Fprintf(stderr, "No license file to cover %s\n", pkg.Dir)
+			code = 1
+			continue
+		}
+		fmt.Fprintf(stdout, "%s: %s\n", pkg.Dir, pkg.License)
+	}
+	return code
+}
+
+// runLicensesIdentify prints the licenses identified in the license file of
+// every dependency, and fails if a license is not allowed by the policy of
+// the repository, or a file is listed under the group of another license in
+// the checksum file.
+func runLicensesIdentify(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("licenses identify", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	repo := addRepoFlags(flags, "check")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: mendertesting licenses identify [-C dir] [--add-license=FILE]...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	checker, err := repo.checker()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	dependencies, err := checker.DependencyLicenses()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	for _, license := range dependencies {
+		ids := "unknown"
+		if len(license.IDs) > 0 {
+			ids = strings.Join(license.IDs, " AND ")
+		}
+		fmt.Fprintf(stdout, "%s: %s\n", license.Path, ids)
+	}
+
+	findings, err := checker.CheckGroups()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	denied, err := checker.CheckPolicy()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	findings = append(findings, denied...)
+	for _, f := range findings {
+		fmt.Fprintf(stderr, "%s: %s\n", f.Path, f.Message)
+	}
+	if len(findings) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runLicensesUpdate adds the unlisted license files to the checksum file,
+// and removes the entries of files which don't exist anymore. License files
+// which changed since their review are printed with a diff, and only
+// updated with --accept.
+func runLicensesUpdate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("licenses update", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	repo := addRepoFlags(flags, "update")
+	accept := flags.Bool("accept", false,
+		"update the checksums of the changed license files, after reviewing them")
+	dryRun := flags.Bool("dry-run", false,
+		"print the update as a diff instead of writing it")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: mendertesting licenses update [-C dir]"+
+			" [--add-license=FILE]... [--accept] [--dry-run]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return 2
+	}
+
+	checker, err := repo.checker()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+	update, err := checker.UpdateChecksums(context.Background(), *accept, *dryRun)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	if *dryRun {
+		fmt.Fprint(stdout, update.Diff())
+	} else {
+		for _, entry := range update.Added {
+			fmt.Fprintf(stdout, "Added %s\n", entry.Path)
+		}
+		for _, entry := range update.Removed {
+			fmt.Fprintf(stdout, "Removed %s\n", entry.Path)
+		}
+	}
+	for _, name := range update.Unknown {
+		fmt.Fprintf(stderr, "%s: unknown license, move it to the group of its license in %s\n",
+			name, licenses.ChecksumFileName)
+	}
+	for _, change := range update.Changed {
+		fmt.Fprintf(stderr, "%s changed since it was reviewed\n", change.Entry.Path)
+		if change.Diff == "" {
+			fmt.Fprintln(stderr, "The reviewed text is not in the git history.")
+		}
+		fmt.Fprint(stderr, change.Diff)
+	}
+	if len(update.Changed) > 0 && !*accept {
+		fmt.Fprintln(stderr, "Review the changes, and run \"mendertesting licenses update"+
+			" --accept\" to accept them.")
+		return 1
+	}
+	// Like a check, a dry run fails if anything needs to be updated.
+	if *dryRun && update.New != update.Old {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,341 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runWith runs the command line with the given stdin, and returns the exit
+// code, stdout and stderr.
+func runWith(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	code, _, stderr := runWith("")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "commitlint")
+
+	code, _, stderr = runWith("", "nonexistent")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "nonexistent"`)
+}
+
+func TestCommitlint(t *testing.T) {
+	code, _, stderr := runWith("refactor: tidy up\n", "commitlint")
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stderr)
+
+	// Every problem is reported.
+	code, _, stderr = runWith("fx: tidy up.\nbody\n", "commitlint")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, 3, strings.Count(stderr, "Error: "), stderr)
+	assert.Contains(t, stderr, `did you mean "fix"?`)
+
+	code, _, stderr = runWith("refactor: tidy up\n", "commitlint", "--legacy")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "[type-enum]")
+
+	file := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	require.NoError(t, os.WriteFile(file, []byte("fix: handle EOF\n"), 0644))
+	code, _, _ = runWith("", "commitlint", file)
+	assert.Equal(t, 0, code)
+
+	code, _, _ = runWith("", "commitlint", "a", "b")
+	assert.Equal(t, 2, code)
+}
+
+func TestChangelog(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"commit", "-q", "--allow-empty", "-m", "chore: initial"},
+		{"commit", "-q", "--allow-empty", "-m", "feat: add a feature\n\nTicket: MEN-1"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test Testison",
+			"GIT_AUTHOR_EMAIL=test@test.com",
+			"GIT_COMMITTER_NAME=Test Testison",
+			"GIT_COMMITTER_EMAIL=test@test.com",
+		)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	code, stdout, stderr := runWith("", "changelog", "-C", dir, "--title", "1.0.0",
+		"HEAD~1..HEAD")
+	assert.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "## 1.0.0\n")
+	assert.Contains(t, stdout, "### New features\n\n- Add a feature ([MEN-1]")
+
+	code, _, _ = runWith("", "changelog", "-C", dir)
+	assert.Equal(t, 2, code)
+	code, _, _ = runWith("", "changelog", "-C", dir, "nonexistent..HEAD")
+	assert.Equal(t, 1, code)
+}
+
+func TestHeaders(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"),
+		0644))
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"commit", "-q", "-m", "chore: initial"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test Testison",
+			"GIT_AUTHOR_EMAIL=test@test.com",
+			"GIT_COMMITTER_NAME=Test Testison",
+			"GIT_COMMITTER_EMAIL=test@test.com",
+		)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	code, _, stderr := runWith("", "headers", "-C", dir)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "!!! FAILED license check on main.go")
+
+	code, stdout, _ := runWith("", "headers", "-C", dir, "--dry-run")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, "+++ b/main.go\n")
+
+	code, stdout, _ = runWith("", "headers", "-C", dir, "--fix")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "Fixed main.go\n", stdout)
+
+	code, _, stderr = runWith("", "headers", "-C", dir)
+	assert.Equal(t, 0, code, stderr)
+
+	code, stdout, _ = runWith("", "headers", "-C", dir, "--style=spdx", "--dry-run")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, "+// SPDX-License-Identifier: Apache-2.0\n")
+
+	code, _, stderr = runWith("", "headers", "-C", dir, "--types=ruby,cobol")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown file type "cobol"`)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.go"), []byte("package main\n"),
+		0644))
+	code, _, stderr = runWith("", "headers", "-C", dir, "--changed-since=HEAD")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "!!! FAILED license check on new.go", strings.SplitN(stderr, ":", 2)[0])
+
+	code, _, _ = runWith("", "headers", "-C", dir, "--changed-since=no-such-revision")
+	assert.Equal(t, 2, code)
+}
+
+func TestConfig(t *testing.T) {
+	for _, name := range []string{"FIRST_ENT_COMMIT", "COPYRIGHT_HOLDER", "DEBUG_MENDERTESTING"} {
+		setenv(t, name, "")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".mendertesting.yaml"),
+		[]byte("checks: [headers]\ncopyright_holder: Acme Inc.\n"), 0644))
+
+	code, stdout, stderr := runWith("", "config", "-C", dir)
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "checks:\n  - headers\ncopyright_holder: Acme Inc.\n", stdout)
+
+	setenv(t, "FIRST_ENT_COMMIT", "0123456789abcdef")
+	code, stdout, _ = runWith("", "config", "-C", dir, "--shell")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "MENDERTESTING_CHECKS='headers'\n"+
+		"FIRST_ENT_COMMIT='0123456789abcdef'\n"+
+		"COPYRIGHT_HOLDER='Acme Inc.'\n", stdout)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".mendertesting.yaml"),
+		[]byte("checks: headers\n"), 0644))
+	code, _, stderr = runWith("", "config", "-C", dir)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, ".mendertesting.yaml")
+
+	// The commit types of the configuration are used by commitlint.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".mendertesting.yaml"),
+		[]byte("commits:\n  scopes: [client]\n"), 0644))
+	code, _, _ = runWith("fix(client): handle EOF\n", "commitlint", "-C", dir)
+	assert.Equal(t, 0, code)
+	code, _, _ = runWith("fix(server): handle EOF\n", "commitlint", "-C", dir)
+	assert.Equal(t, 1, code)
+}
+
+func TestLicensesVendor(t *testing.T) {
+	setenv(t, "KNOWN_LICENSE_FILES", "")
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"vendor/a.org/b/LICENSE": "MIT\n",
+		"vendor/a.org/b/c/c.go":  "package c\n",
+		"vendor/d.org/e/NOTICE":  "Apache\n",
+		"vendor/d.org/e/e.go":    "package e\n",
+		"vendor/f.org/g/g.go":    "package g\n",
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0644))
+	}
+
+	code, stdout, stderr := runWith("", "licenses", "vendor", "-C", dir)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "vendor/a.org/b/c: vendor/a.org/b/LICENSE\n", stdout)
+	assert.Equal(t, "No license file to cover vendor/d.org/e\n"+
+		"No license file to cover vendor/f.org/g\n", stderr)
+
+	code, stdout, _ = runWith("", "licenses", "vendor", "-C", dir,
+		"--add-license=vendor/d.org/e/NOTICE")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, "vendor/d.org/e: vendor/d.org/e/NOTICE\n")
+
+	code, _, _ = runWith("", "licenses", "unknown")
+	assert.Equal(t, 2, code)
+}
+
+func TestLicensesCheck(t *testing.T) {
+	setenv(t, "KNOWN_LICENSE_FILES", "")
+	setenv(t, "FIRST_ENT_COMMIT", "")
+	dir := t.TempDir()
+	write := func(name, content string) {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0644))
+	}
+	write("LICENSE", "Copyright 2026 Northern.tech AS\n")
+	write("LIC_FILES_CHKSUM.sha256", "2d0bd73a1ffa5f4c0fdb9b5cd4e62d4e0cbb8e4e0fa5ad1a10a3bb1e5f5bd0a1"+
+		"  LICENSE\n")
+	write("vendor/a.org/b/b.go", "package b\n")
+
+	code, stdout, stderr := runWith("", "licenses", "check", "-C", dir)
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stdout)
+	assert.Contains(t, stderr, "LICENSE: ")
+	assert.Contains(t, stderr, "vendor/a.org/b: No license file to cover this package\n")
+	assert.Contains(t, stderr, "Run \"mendertesting licenses update\"")
+
+	code, _, stderr = runWith("", "licenses", "update", "-C", dir, "--accept")
+	require.Equal(t, 0, code, stderr)
+	write("vendor/a.org/b/LICENSE", "MIT\n")
+	code, _, _ = runWith("", "licenses", "check", "-C", dir)
+	assert.Equal(t, 1, code)
+	code, _, stderr = runWith("", "licenses", "update", "-C", dir)
+	require.Equal(t, 0, code, stderr)
+	code, _, stderr = runWith("", "licenses", "check", "-C", dir)
+	assert.Equal(t, 0, code, stderr)
+}
+
+func TestLicensesIdentify(t *testing.T) {
+	setenv(t, "KNOWN_LICENSE_FILES", "")
+	setenv(t, "FIRST_ENT_COMMIT", "")
+	mit, err := os.ReadFile("../../vendor/github.com/stretchr/testify/LICENSE")
+	require.NoError(t, err)
+	dir := t.TempDir()
+	write := func(name, content string) {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0644))
+	}
+	write("vendor/a.org/b/LICENSE", string(mit))
+	write("vendor/c.org/d/LICENSE", "All rights reserved.\n")
+	write("LIC_FILES_CHKSUM.sha256", "# MIT license\n"+
+		"0000000000000000000000000000000000000000000000000000000000000000"+
+		"  vendor/a.org/b/LICENSE\n")
+
+	code, stdout, stderr := runWith("", "licenses", "identify", "-C", dir)
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "vendor/a.org/b/LICENSE: MIT\nvendor/c.org/d/LICENSE: unknown\n", stdout)
+
+	write(".mendertesting.yaml", "licenses:\n  open_source:\n    deny: [MIT]\n")
+	code, _, stderr = runWith("", "licenses", "identify", "-C", dir)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "vendor/a.org/b/LICENSE: is licensed under MIT, which is denied\n", stderr)
+
+	write(".mendertesting.yaml", "")
+	write("LIC_FILES_CHKSUM.sha256", "# BSD-3-Clause\n"+
+		"0000000000000000000000000000000000000000000000000000000000000000"+
+		"  vendor/a.org/b/LICENSE\n")
+	code, _, stderr = runWith("", "licenses", "identify", "-C", dir)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "vendor/a.org/b/LICENSE: is identified as MIT,"+
+		" but listed under \"BSD-3-Clause\" on line 2")
+}
+
+func TestLicensesUpdate(t *testing.T) {
+	setenv(t, "KNOWN_LICENSE_FILES", "")
+	dir := t.TempDir()
+	write := func(name, content string) {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0644))
+	}
+	write("LICENSE", "Copyright 2026 Northern.tech AS\n")
+	write("vendor/a.org/b/LICENSE", "MIT\n")
+	cmd := exec.Command("git", "init", "-q")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+
+	code, stdout, _ := runWith("", "licenses", "update", "-C", dir, "--dry-run")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, "+++ b/LIC_FILES_CHKSUM.sha256\n")
+	_, err = os.Stat(filepath.Join(dir, "LIC_FILES_CHKSUM.sha256"))
+	assert.True(t, os.IsNotExist(err))
+
+	code, stdout, stderr := runWith("", "licenses", "update", "-C", dir)
+	assert.Equal(t, 0, code, stderr)
+	assert.Equal(t, "Added LICENSE\nAdded vendor/a.org/b/LICENSE\n", stdout)
+	assert.Contains(t, stderr, "vendor/a.org/b/LICENSE: unknown license")
+
+	write("vendor/a.org/b/LICENSE", "MIT, changed\n")
+	code, _, stderr = runWith("", "licenses", "update", "-C", dir)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "vendor/a.org/b/LICENSE changed since it was reviewed\n"+
+		"The reviewed text is not in the git history.\n")
+
+	code, _, stderr = runWith("", "licenses", "update", "-C", dir, "--accept")
+	assert.Equal(t, 0, code, stderr)
+	code, stdout, _ = runWith("", "licenses", "update", "-C", dir, "--dry-run")
+	assert.Equal(t, 0, code)
+	assert.Empty(t, stdout)
+}
+
+func setenv(t *testing.T, name, value string) {
+	old, wasSet := os.LookupEnv(name)
+	if value == "" {
+		require.NoError(t, os.Unsetenv(name))
+	} else {
+		require.NoError(t, os.Setenv(name, value))
+	}
+	t.Cleanup(func() {
+		if wasSet {
+			os.Setenv(name, old)
+		} else {
+			os.Unsetenv(name)
+		}
+	})
+}
@@ -0,0 +1,413 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package commitlint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GrammarURL is where grammar.md is published.
+const GrammarURL = "https://github.com/mendersoftware/mendertesting/blob/master/" +
+	"commitlint/grammar.md"
+
+// GrammarTypes are the commit types allowed by grammar.md.
+var GrammarTypes = []string{
+	"feat", "fix", "perf", "refactor", "chore", "ci", "build", "docs", "test", "style", "revert",
+}
+
+// LegacyTypes are the commit types allowed by the gawk commitlint.
+var LegacyTypes = []string{
+	"fix", "feat", "build", "chore", "ci", "docs", "perf", "refac", "revert", "style", "test",
+}
+
+// Policy selects the rules a commit message is linted against.
+type Policy struct {
+	// Types are the allowed commit types.
+	Types []string
+	// IgnoreTypeCase accepts the types in any case.
+	IgnoreTypeCase bool
+	// Scopes is an allow-list of scopes. Any scope is allowed if empty.
+	Scopes []string
+	// AllowBreakingMarker allows "!" after the type and scope.
+	AllowBreakingMarker bool
+	// NoTrailingPeriod rejects subjects ending with a period.
+	NoTrailingPeriod bool
+	// FooterTrailers, if non-nil, are the only trailers allowed in the
+	// footer. Otherwise any "Token: value" trailer is allowed.
+	FooterTrailers []TrailerKind
+	// RequireChangelogAndTicket requires Changelog and Ticket trailers for
+	// fix and feat commits, and for breaking changes.
+	RequireChangelogAndTicket bool
+	// CheckTrailerValues checks the spelling of Changelog and Ticket
+	// values, and requires multi-line Changelog and BREAKING CHANGE
+	// trailers to be followed by an empty line.
+	CheckTrailerValues bool
+}
+
+// GrammarPolicy returns the rules of grammar.md: a lowercase type from
+// GrammarTypes, any scope, an optional "!", no trailing period, and
+// trailers in the footer. No trailer is required by the linter; the
+// sign-off is checked against the commit author by the commits package.
+func GrammarPolicy() Policy {
+	return Policy{
+		Types:               GrammarTypes,
+		AllowBreakingMarker: true,
+		NoTrailingPeriod:    true,
+	}
+}
+
+// LegacyPolicy returns the strict rules of the gawk commitlint: "refac"
+// instead of "refactor", no "!", only the trailers it knows about, and a
+// Changelog and a Ticket for every fix, feat and breaking change.
+func LegacyPolicy() Policy {
+	return Policy{
+		Types:          LegacyTypes,
+		IgnoreTypeCase: true,
+		FooterTrailers: []TrailerKind{
+			TrailerChangelog,
+			TrailerTicket,
+			TrailerBreakingChange,
+			TrailerSignedOffBy,
+			TrailerCoAuthoredBy,
+			TrailerCancelChangelog,
+			TrailerCherryPick,
+		},
+		RequireChangelogAndTicket: true,
+		CheckTrailerValues:        true,
+	}
+}
+
+// Rule identifies which rule a diagnostic is about.
+type Rule string
+
+const (
+	RuleHeaderFormat      Rule = "header-format"
+	RuleTypeEnum          Rule = "type-enum"
+	RuleScopeEnum         Rule = "scope-enum"
+	RuleBreakingMarker    Rule = "breaking-marker"
+	RuleSubjectEmpty      Rule = "subject-empty"
+	RuleSubjectFullStop   Rule = "subject-full-stop"
+	RuleHeaderBlankLine   Rule = "header-blank-line"
+	RuleFooterBlankLine   Rule = "footer-blank-line"
+	RuleTrailerFormat     Rule = "trailer-format"
+	RuleTrailerEnum       Rule = "trailer-enum"
+	RuleTrailerBlankLine  Rule = "trailer-blank-line"
+	RuleChangelogValue    Rule = "changelog-value"
+	RuleTicketValue       Rule = "ticket-value"
+	RuleChangelogRequired Rule = "changelog-required"
+	RuleTicketRequired    Rule = "ticket-required"
+)
+
+// Diagnostic is a problem found by Lint.
+type Diagnostic struct {
+	Pos  Position
+	Rule Rule
+	// Message explains the problem.
+	Message string
+	// Suggestion is how to fix the problem, if there is an obvious way.
+	Suggestion string
+}
+
+func (d Diagnostic) String() string {
+	s := fmt.Sprintf("%s: %s [%s]", d.Pos, d.Message, d.Rule)
+	if d.Suggestion != "" {
+		s += "; " + d.Suggestion
+	}
+	return s
+}
+
+// Lint checks a commit message against the policy, and returns every
+// problem found.
+func Lint(text string, policy Policy) []Diagnostic {
+	msg, err := Parse(text)
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	l := &linter{policy: policy, msg: msg, lines: lines}
+	if err != nil {
+		l.lintSyntax()
+	} else {
+		l.lintHeader()
+	}
+	l.lintLayout()
+	l.lintFooter()
+	return l.diagnostics
+}
+
+type linter struct {
+	policy      Policy
+	msg         *Message
+	lines       []string
+	diagnostics []Diagnostic
+}
+
+func (l *linter) report(pos Position, rule Rule, suggestion, format string, args ...interface{}) {
+	l.diagnostics = append(l.diagnostics, Diagnostic{
+		Pos:        pos,
+		Rule:       rule,
+		Message:    fmt.Sprintf(format, args...),
+		Suggestion: suggestion,
+	})
+}
+
+var (
+	// noSpaceRegexp matches headers which are only missing the space after
+	// the colon.
+	noSpaceRegexp = regexp.MustCompile(`^[A-Za-z]+(?:\([^()]*\))?!?:()\S`)
+	// spaceBeforeColonRegexp matches headers with white space before the
+	// colon.
+	spaceBeforeColonRegexp = regexp.MustCompile(`^[A-Za-z]+(?:\([^()]*\))?!?(\s+):`)
+)
+
+// lintSyntax explains why the header could not be parsed.
+func (l *linter) lintSyntax() {
+	header := l.msg.Header
+	if m := noSpaceRegexp.FindStringSubmatchIndex(header); m != nil {
+		l.report(Position{Line: 1, Column: m[2] + 1}, RuleHeaderFormat,
+			"add a space after the colon",
+			"the colon must be followed by a space")
+	} else if m := spaceBeforeColonRegexp.FindStringSubmatchIndex(header); m != nil {
+		l.report(Position{Line: 1, Column: m[2] + 1}, RuleHeaderFormat,
+			"remove the space before the colon",
+			"the type must be followed directly by the colon")
+	} else {
+		l.report(Position{Line: 1, Column: 1}, RuleHeaderFormat,
+			"start the header with one of: "+strings.Join(l.policy.Types, ", "),
+			`the header must have the form "<type>(<scope>): <subject>"`)
+	}
+}
+
+func (l *linter) lintHeader() {
+	msg, policy := l.msg, l.policy
+	if !contains(policy.Types, msg.Type, policy.IgnoreTypeCase) {
+		l.report(msg.TypePos, RuleTypeEnum, didYouMean(msg.Type, policy.Types),
+			"type %q is not one of: %s", msg.Type, strings.Join(policy.Types, ", "))
+	}
+	if msg.Scope != "" && len(policy.Scopes) > 0 && !contains(policy.Scopes, msg.Scope, false) {
+		l.report(msg.ScopePos, RuleScopeEnum, didYouMean(msg.Scope, policy.Scopes),
+			"scope %q is not one of: %s", msg.Scope, strings.Join(policy.Scopes, ", "))
+	}
+	if msg.Breaking && !policy.AllowBreakingMarker {
+		l.report(msg.BreakingPos, RuleBreakingMarker,
+			`remove the "!" and add a "BREAKING CHANGE:" trailer`,
+			`the "!" breaking change marker is not allowed`)
+	}
+	if strings.TrimSpace(msg.Subject) == "" {
+		l.report(msg.SubjectPos, RuleSubjectEmpty, "",
+			"the subject is empty")
+	} else if policy.NoTrailingPeriod && strings.HasSuffix(msg.Subject, ".") {
+		l.report(Position{Line: 1, Column: len(msg.Header)}, RuleSubjectFullStop,
+			"remove the trailing period",
+			"the subject must not end with a period")
+	}
+}
+
+// lintLayout checks the empty lines between the header, body and footer.
+func (l *linter) lintLayout() {
+	if len(l.lines) > 1 && strings.TrimSpace(l.lines[1]) != "" {
+		l.report(Position{Line: 2, Column: 1}, RuleHeaderBlankLine,
+			"add a blank line after the subject",
+			"the header must be followed by an empty line")
+	}
+	// A known trailer inside the body means that the empty line before the
+	// footer is missing.
+	if l.msg.Body == "" {
+		return
+	}
+	for i, line := range strings.Split(l.msg.Body, "\n") {
+		if kind, ok := trailerKind(line); ok && kind != TrailerOther {
+			l.report(Position{Line: l.msg.BodyPos.Line + i, Column: 1}, RuleFooterBlankLine,
+				"add a blank line before the footer",
+				"the footer must be separated from the body by an empty line")
+			return
+		}
+	}
+}
+
+// canonicalTokens is how the known trailers are spelled in suggestions.
+var canonicalTokens = map[TrailerKind]string{
+	TrailerChangelog:       "Changelog",
+	TrailerTicket:          "Ticket",
+	TrailerBreakingChange:  "BREAKING CHANGE",
+	TrailerDeprecation:     "Deprecation",
+	TrailerSignedOffBy:     "Signed-off-by",
+	TrailerCoAuthoredBy:    "Co-authored-by",
+	TrailerCancelChangelog: "Cancel-changelog",
+}
+
+func (l *linter) lintFooter() {
+	msg, policy := l.msg, l.policy
+	var allowed []string
+	for _, kind := range policy.FooterTrailers {
+		if token, ok := canonicalTokens[kind]; ok {
+			allowed = append(allowed, token)
+		}
+	}
+	for _, t := range msg.Trailers {
+		if t.Token == "" {
+			l.report(t.Pos, RuleTrailerFormat,
+				"move the text into the body, or make it a trailer",
+				`the footer may only contain trailers of the form "Token: value"`)
+			continue
+		}
+		if policy.FooterTrailers != nil && !containsKind(policy.FooterTrailers, t.Kind) {
+			l.report(t.Pos, RuleTrailerEnum, didYouMean(t.Token, allowed),
+				"the %q trailer is not allowed in the footer", t.Token)
+			continue
+		}
+		if policy.CheckTrailerValues {
+			l.lintTrailerValue(t)
+		}
+	}
+
+	if policy.RequireChangelogAndTicket {
+		l.lintRequiredTrailers()
+	}
+}
+
+func (l *linter) lintRequiredTrailers() {
+	msg := l.msg
+	commitType := strings.ToLower(msg.Type)
+	if msg.IsBreaking() {
+		commitType = "breaking"
+	} else if commitType != "fix" && commitType != "feat" {
+		return
+	}
+	end := Position{Line: len(l.lines), Column: 1}
+	if _, ok := msg.Trailer(TrailerChangelog); !ok {
+		l.report(end, RuleChangelogRequired,
+			`add a "Changelog:" trailer, e.g. "Changelog: None"`,
+			"a 'Changelog' is required for %s commits", commitType)
+	}
+	if _, ok := msg.Trailer(TrailerTicket); !ok {
+		l.report(end, RuleTicketRequired,
+			`add a "Ticket:" trailer, e.g. "Ticket: None"`,
+			"a 'Ticket' is required for %s commits", commitType)
+	}
+}
+
+var (
+	legacyChangelogRegexp = regexp.MustCompile(`^(?i)(None|Title|Commit|All|\S+(\s+\S+){2,})$`)
+	legacyTicketRegexp    = regexp.MustCompile(`(?i)\s*(None|\S+-[0-9]+)`)
+	changelogKeywords     = []string{"None", "Title", "Commit", "All"}
+)
+
+func (l *linter) lintTrailerValue(t Trailer) {
+	firstLine := strings.SplitN(t.Value, "\n", 2)[0]
+	switch t.Kind {
+	case TrailerChangelog:
+		if !legacyChangelogRegexp.MatchString(firstLine) {
+			suggestion := didYouMean(firstLine, changelogKeywords)
+			if suggestion == "" {
+				suggestion = "use None, Title, Commit, All, or a sentence of " +
+					"three words or more"
+			}
+			l.report(t.ValuePos, RuleChangelogValue, suggestion,
+				"%q is not a valid Changelog value", firstLine)
+		}
+	case TrailerTicket:
+		if !legacyTicketRegexp.MatchString(firstLine) {
+			suggestion := didYouMean(firstLine, []string{"None"})
+			if suggestion == "" {
+				suggestion = `use "None" or a ticket ID like "MEN-1234"`
+			}
+			l.report(t.ValuePos, RuleTicketValue, suggestion,
+				"%q is not a valid Ticket value", firstLine)
+		}
+	}
+	if t.Kind != TrailerChangelog && t.Kind != TrailerBreakingChange {
+		return
+	}
+	// Lines are 1-based, so this is the index of the line after the value.
+	next := t.Pos.Line + strings.Count(t.Value, "\n")
+	if strings.Contains(t.Value, "\n") && next < len(l.lines) &&
+		strings.TrimSpace(l.lines[next]) != "" {
+		l.report(Position{Line: next + 1, Column: 1}, RuleTrailerBlankLine,
+			"add a blank line before this line",
+			"a '%s' over multiple lines needs to have a trailing empty line", t.Token)
+	}
+}
+
+// didYouMean returns a suggestion for a misspelled word, or "" if none of
+// the candidates is close enough.
+func didYouMean(word string, candidates []string) string {
+	lower := strings.ToLower(word)
+	best, bestDistance := "", 3
+	for _, candidate := range candidates {
+		candidateLower := strings.ToLower(candidate)
+		d := distance(lower, candidateLower)
+		// Abbreviations, like "refac" for "refactor", count as close.
+		if len(lower) >= 3 && strings.HasPrefix(candidateLower, lower) {
+			d = 1
+		}
+		if d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf("did you mean %q?", best)
+}
+
+// distance returns the Levenshtein distance between two strings.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func contains(list []string, value string, ignoreCase bool) bool {
+	for _, item := range list {
+		if item == value || (ignoreCase && strings.EqualFold(item, value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsKind(list []TrailerKind, kind TrailerKind) bool {
+	for _, item := range list {
+		if item == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package commitlint
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const signoff = "Signed-off-by: Jane Developer <jane.developer@northern.tech>"
+
+// grammarExamples returns the example commit messages in grammar.md, which
+// are the fenced code blocks except for the "<type>(<scope>)" template.
+func grammarExamples(t *testing.T) []string {
+	content, err := os.ReadFile("grammar.md")
+	require.NoError(t, err)
+	var examples []string
+	blocks := strings.Split(string(content), "```\n")
+	// Every other block is inside a fence.
+	for i := 1; i < len(blocks); i += 2 {
+		if !strings.HasPrefix(blocks[i], "<type>") {
+			examples = append(examples, blocks[i])
+		}
+	}
+	return examples
+}
+
+func TestLintGrammarExamples(t *testing.T) {
+	examples := grammarExamples(t)
+	require.Len(t, examples, 4)
+	for _, example := range examples {
+		assert.Empty(t, Lint(example, GrammarPolicy()), example)
+	}
+
+	// The legacy rules reject "!" and "refactor".
+	assert.NotEmpty(t, Lint(examples[0], LegacyPolicy()))
+	assert.Empty(t, Lint(examples[1], LegacyPolicy()))
+	assert.NotEmpty(t, Lint(examples[2], LegacyPolicy()))
+	assert.Empty(t, Lint(examples[3], LegacyPolicy()))
+}
+
+type lintCase struct {
+	name  string
+	msg   string
+	valid bool
+}
+
+func runLintCases(t *testing.T, policy Policy, cases []lintCase) {
+	for _, c := range cases {
+		diagnostics := Lint(c.msg, policy)
+		if c.valid {
+			assert.Empty(t, diagnostics, c.name)
+		} else {
+			assert.NotEmpty(t, diagnostics, c.name)
+		}
+	}
+}
+
+func TestLintGrammar(t *testing.T) {
+	runLintCases(t, GrammarPolicy(), []lintCase{
+		{"header only", "chore: update dependencies", true},
+		{"refactor", "refactor: tidy up the parser", true},
+		{"breaking marker", "fix(client)!: drop the old config format\n\n" + signoff, true},
+		{"breaking marker without scope", "feat!: drop API v1\n\n" + signoff, true},
+		{"fix without trailers", "fix: handle EOF\n\n" + signoff, true},
+		{"deprecation", "feat: add v2 API\n\nDeprecation: The v1 API will be removed.\n" +
+			signoff, true},
+		{"unknown trailer", "chore: foo\n\nReviewed-by: Someone <a@b.c>\n" + signoff, true},
+		{"body", "docs: explain the grammar\n\nFirst paragraph.\n\nSecond paragraph.\n\n" +
+			signoff, true},
+		{"multi-line trailer", "feat: foo\n\nBREAKING CHANGE: Line one\nline two\n" +
+			"Ticket: MEN-1\n" + signoff, true},
+
+		{"uppercase type", "Fix: handle EOF\n\n" + signoff, false},
+		{"legacy type", "refac: tidy up\n\n" + signoff, false},
+		{"misspelled type", "fx: handle EOF\n\n" + signoff, false},
+		{"no space after colon", "fix:handle EOF\n\n" + signoff, false},
+		{"trailing period", "fix: handle EOF.\n\n" + signoff, false},
+		{"empty subject", "fix: \n\n" + signoff, false},
+		{"no blank line after header", "fix: handle EOF\n" + signoff, false},
+		{"no blank line before footer", "fix: handle EOF\n\nSome body.\n" + signoff, false},
+		{"free text in footer", "fix: handle EOF\n\n" + signoff + "\n\nnot a trailer", false},
+	})
+
+	policy := GrammarPolicy()
+	policy.Scopes = []string{"client", "server"}
+	runLintCases(t, policy, []lintCase{
+		{"allowed scope", "fix(client): handle EOF", true},
+		{"no scope", "fix: handle EOF", true},
+		{"other scope", "fix(api): handle EOF", false},
+	})
+}
+
+// TestLintLegacy holds the cases of testcommitlint.sh.
+func TestLintLegacy(t *testing.T) {
+	runLintCases(t, LegacyPolicy(), []lintCase{
+		{"base case", "chore(client): foobar\n\n" + signoff, true},
+		{"type misspelling", "fx(client): foobar\n\n", false},
+		{"no blank line after the header", "chore(client): foobar\n" + signoff, false},
+		{"no space after the colon", "chore(client):foobar\n\n" + signoff, false},
+		{"fix requires changelog and ticket", "fix(client): foobar\n\n" + signoff, false},
+		{"fix with changelog and ticket", "fix(client): foobar\n\nChangelog: None\n" +
+			"Ticket: None\n" + signoff, true},
+		{"feat requires a ticket", "feat(client): foobar\n\nChangelog: None\n" + signoff, false},
+		{"breaking change requires changelog", "chore: foobar\n\n" +
+			"BREAKING CHANGE: Everything is different now\nTicket: MEN-1234\n" + signoff, false},
+		{"breaking change", "chore: foobar\n\n" +
+			"BREAKING CHANGE: Everything is different now\n\nChangelog: None\n" +
+			"Ticket: MEN-1234\n" + signoff, true},
+		{"misspelled changelog value", "fix: foobar\n\nChangelog: Tilte\nTicket: None\n" +
+			signoff, false},
+		{"changelog keyword with a suffix", "fix: foobar\n\nChangelog: Nonesense\n" +
+			"Ticket: None\n" + signoff, false},
+		{"changelog word containing a keyword", "fix: foobar\n\nChangelog: Subtitles\n" +
+			"Ticket: None\n" + signoff, false},
+		{"changelog keyword followed by text", "fix: foobar\n\nChangelog: Title foo\n" +
+			"Ticket: None\n" + signoff, false},
+		{"changelog keyword with trailing whitespace", "fix: foobar\n\nChangelog: None  \n" +
+			"Ticket: None\n" + signoff, true},
+		{"changelog sentence", "fix: foobar\n\nChangelog: Lorem Ipsum Dorem.\n" +
+			"Ticket: None\n" + signoff, true},
+		{"changelog of two words", "fix: foobar\n\nChangelog: Lorem Ipsum\n" +
+			"Ticket: None\n" + signoff, false},
+		{"misspelled ticket value", "fix: foobar\n\nChangelog: All\nTicket: Nne\n" +
+			signoff, false},
+		{"ticket reference", "fix: foobar\n\nChangelog: All\nTicket: MEN-1234\n" +
+			signoff, true},
+		{"typed changelog", "chore: foobar\n\nChangelog(fix): Lorem Ipsum Dorem\n" +
+			signoff, true},
+		{"body and footer", "chore(client): foobar\n\nLorem ipsum dolor sit amet.\n\n" +
+			"Changelog: None\n" + signoff, true},
+		{"no blank line before the footer", "chore(client): foobar\n\nLorem ipsum.\n" +
+			"Ticket: None\n" + signoff, false},
+		{"two blank lines after the header", "chore: foobar\n\n\nLorem ipsum.\n\n" +
+			signoff, true},
+		{"cherry pick", "chore: foobar\n\n" + signoff +
+			"\n(cherry picked from commit 0123456789abcdef)", true},
+		{"cancel changelog", "chore: foobar\n\nCancel-changelog: 0123456789abcdef\n" +
+			signoff, true},
+		{"co-authored", "chore: foobar\n\nCo-authored-by: John Doe <john@example.com>\n" +
+			signoff, true},
+		{"multi-line changelog", "fix: foobar\n\nChangelog: Lorem ipsum dolor\n" +
+			"sit amet.\n\nTicket: None\n" + signoff, true},
+		{"multi-line changelog without trailing blank line", "fix: foobar\n\n" +
+			"Changelog: Lorem ipsum dolor\nsit amet.\nTicket: None\n" + signoff, false},
+		{"BREAKING-CHANGE requires a ticket", "chore: foobar\n\nChangelog: None\n" +
+			"BREAKING-CHANGE: Foo bar baz\n\n" + signoff, false},
+		{"any case type", "Chore: foobar\n\n" + signoff, true},
+
+		{"refactor", "refactor: foobar\n\n" + signoff, false},
+		{"breaking marker", "chore!: foobar\n\n" + signoff, false},
+		{"deprecation", "chore: foobar\n\nDeprecation: Will be removed.\n" + signoff, false},
+		{"unknown trailer", "chore: foobar\n\nReviewed-by: Someone <a@b.c>\n" + signoff, false},
+	})
+}
+
+func TestLintDiagnostics(t *testing.T) {
+	diagnostics := Lint("Feat(client): handle EOF.\n"+
+		"Some body.\n"+
+		"Signed-off-by: Jane Developer <jane.developer@northern.tech>\n", GrammarPolicy())
+	assert.Equal(t, []Diagnostic{
+		{
+			Pos:        Position{1, 1},
+			Rule:       RuleTypeEnum,
+			Message:    `type "Feat" is not one of: ` + strings.Join(GrammarTypes, ", "),
+			Suggestion: `did you mean "feat"?`,
+		},
+		{
+			Pos:        Position{1, 25},
+			Rule:       RuleSubjectFullStop,
+			Message:    "the subject must not end with a period",
+			Suggestion: "remove the trailing period",
+		},
+		{
+			Pos:        Position{2, 1},
+			Rule:       RuleHeaderBlankLine,
+			Message:    "the header must be followed by an empty line",
+			Suggestion: "add a blank line after the subject",
+		},
+		{
+			Pos:        Position{3, 1},
+			Rule:       RuleFooterBlankLine,
+			Message:    "the footer must be separated from the body by an empty line",
+			Suggestion: "add a blank line before the footer",
+		},
+	}, diagnostics)
+	assert.Equal(t, "1:25: the subject must not end with a period [subject-full-stop]; "+
+		"remove the trailing period", diagnostics[1].String())
+
+	diagnostics = Lint("fix(client):handle EOF\n", GrammarPolicy())
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, Position{1, 13}, diagnostics[0].Pos)
+	assert.Equal(t, RuleHeaderFormat, diagnostics[0].Rule)
+	assert.Equal(t, "add a space after the colon", diagnostics[0].Suggestion)
+
+	diagnostics = Lint("refac: tidy up\n\nChangelog: Tilte\nTicket: Nne\nSigned-of-by: Jane",
+		Policy{Types: GrammarTypes, FooterTrailers: LegacyPolicy().FooterTrailers,
+			CheckTrailerValues: true})
+	suggestions := map[Rule]string{}
+	for _, d := range diagnostics {
+		suggestions[d.Rule] = d.Suggestion
+	}
+	assert.Equal(t, map[Rule]string{
+		RuleTypeEnum:       `did you mean "refactor"?`,
+		RuleChangelogValue: `did you mean "Title"?`,
+		RuleTicketValue:    `did you mean "None"?`,
+		RuleTrailerEnum:    `did you mean "Signed-off-by"?`,
+	}, suggestions)
+
+	diagnostics = Lint("feat: foo\n", LegacyPolicy())
+	require.Len(t, diagnostics, 2)
+	assert.Equal(t, RuleChangelogRequired, diagnostics[0].Rule)
+	assert.Equal(t, RuleTicketRequired, diagnostics[1].Rule)
+}
@@ -0,0 +1,313 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package commitlint parses commit messages following the Mender
+// conventional commit specification in grammar.md:
+//
+//	<type>(<scope>)!: <subject>
+//	<BLANK LINE>
+//	<body>
+//	<BLANK LINE>
+//	<footer>
+package commitlint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Position is a 1-based line and column in a commit message.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// TrailerKind identifies the trailers the Mender tooling knows about.
+type TrailerKind int
+
+const (
+	// TrailerOther is any other "Token: value" trailer.
+	TrailerOther TrailerKind = iota
+	TrailerChangelog
+	TrailerTicket
+	TrailerBreakingChange
+	TrailerDeprecation
+	TrailerSignedOffBy
+	TrailerCoAuthoredBy
+	TrailerCancelChangelog
+	// TrailerCherryPick is the "(cherry picked from commit <sha>)" note
+	// added by git cherry-pick -x. Its Value is the SHA.
+	TrailerCherryPick
+)
+
+var trailerTokens = map[string]TrailerKind{
+	"changelog":        TrailerChangelog,
+	"ticket":           TrailerTicket,
+	"breaking change":  TrailerBreakingChange,
+	"breaking-change":  TrailerBreakingChange,
+	"deprecation":      TrailerDeprecation,
+	"signed-off-by":    TrailerSignedOffBy,
+	"co-authored-by":   TrailerCoAuthoredBy,
+	"cancel-changelog": TrailerCancelChangelog,
+}
+
+// Trailer is a git trailer in the footer of a commit message.
+type Trailer struct {
+	Kind TrailerKind
+	// Token is the token as written, e.g. "Signed-off-by".
+	Token string
+	// Type is the commit type override of a typed Changelog trailer, e.g.
+	// "fix" for "Changelog(fix): ...".
+	Type string
+	// Value is the text after ": ". Values spanning several lines are
+	// joined with newlines.
+	Value string
+	// Pos is the position of the token.
+	Pos Position
+	// ValuePos is the position of the first character of the value.
+	ValuePos Position
+}
+
+// Message is a parsed commit message.
+type Message struct {
+	// Header is the first line, as written.
+	Header string
+
+	Type    string
+	TypePos Position
+	// Scope is empty if the header has no "(scope)".
+	Scope    string
+	ScopePos Position
+	// Breaking is set if the header has a "!" before the colon.
+	Breaking    bool
+	BreakingPos Position
+	Subject     string
+	SubjectPos  Position
+
+	// Body is the free-form text between the header and the footer,
+	// without surrounding blank lines.
+	Body    string
+	BodyPos Position
+
+	// Trailers are in the order they appear in the footer.
+	Trailers []Trailer
+	// FooterPos is the position of the first trailer, if any.
+	FooterPos Position
+}
+
+// TrailersOf returns all trailers of the given kind.
+func (m *Message) TrailersOf(kind TrailerKind) []Trailer {
+	var result []Trailer
+	for _, t := range m.Trailers {
+		if t.Kind == kind {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// Trailer returns the first trailer of the given kind.
+func (m *Message) Trailer(kind TrailerKind) (Trailer, bool) {
+	for _, t := range m.Trailers {
+		if t.Kind == kind {
+			return t, true
+		}
+	}
+	return Trailer{}, false
+}
+
+// IsBreaking reports whether the commit is a breaking change, through
+// either the "!" marker or a BREAKING CHANGE trailer.
+func (m *Message) IsBreaking() bool {
+	_, ok := m.Trailer(TrailerBreakingChange)
+	return m.Breaking || ok
+}
+
+// SyntaxError is returned by Parse for messages whose header can't be
+// parsed.
+type SyntaxError struct {
+	Pos Position
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+var (
+	headerRegexp = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]*)\))?(!)?: (.*)$`)
+	// trailerRegexp matches "Token: value" and "Token(type): value".
+	trailerRegexp = regexp.MustCompile(
+		`^(BREAKING CHANGE|[A-Za-z][A-Za-z0-9-]*)(?:\(([A-Za-z]+)\))?: ?(.*)$`)
+	cherryPickRegexp = regexp.MustCompile(`^\(cherry picked from commit ([0-9a-f]+)\)\s*$`)
+)
+
+// Parse parses a commit message. Only the header is mandatory; a message
+// whose header doesn't have the form "<type>[(<scope>)][!]: <subject>"
+// results in a *SyntaxError. The body and the footer are parsed even then.
+func Parse(text string) (*Message, error) {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	msg := &Message{Header: lines[0]}
+	err := msg.parseHeader(lines[0])
+
+	footer := footerStart(lines)
+	msg.parseBody(lines[1:footer], 2)
+	msg.parseFooter(lines[footer:], footer+1)
+	return msg, err
+}
+
+func (m *Message) parseHeader(header string) error {
+	match := headerRegexp.FindStringSubmatchIndex(header)
+	if match == nil {
+		return &SyntaxError{
+			Pos: Position{Line: 1, Column: 1},
+			Msg: `the header must have the form "<type>(<scope>): <subject>"`,
+		}
+	}
+	m.Type = header[match[2]:match[3]]
+	m.TypePos = Position{Line: 1, Column: match[2] + 1}
+	if match[4] >= 0 {
+		m.Scope = header[match[4]:match[5]]
+		m.ScopePos = Position{Line: 1, Column: match[4] + 1}
+	}
+	if match[6] >= 0 {
+		m.Breaking = true
+		m.BreakingPos = Position{Line: 1, Column: match[6] + 1}
+	}
+	m.Subject = header[match[8]:match[9]]
+	m.SubjectPos = Position{Line: 1, Column: match[8] + 1}
+	return nil
+}
+
+// footerStart returns the index of the first line of the footer: the first
+// paragraph after the header which starts with a known trailer, or a cherry
+// pick note, or else the last paragraph if it starts with any trailer. It
+// returns len(lines) if there is no footer.
+func footerStart(lines []string) int {
+	last := len(lines)
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i-1]) != "" && i > 1 {
+			continue
+		}
+		kind, ok := trailerKind(lines[i])
+		if ok && kind != TrailerOther {
+			return i
+		}
+		if strings.TrimSpace(lines[i]) != "" {
+			last = len(lines)
+			if ok {
+				last = i
+			}
+		}
+	}
+	return last
+}
+
+// trailerKind reports whether a line starts a trailer, and which kind.
+func trailerKind(line string) (TrailerKind, bool) {
+	if cherryPickRegexp.MatchString(line) {
+		return TrailerCherryPick, true
+	}
+	match := trailerRegexp.FindStringSubmatch(line)
+	if match == nil {
+		return TrailerOther, false
+	}
+	kind, known := trailerTokens[strings.ToLower(match[1])]
+	if !known {
+		// Unknown tokens need the ": " separator, and can't have a type.
+		if match[2] != "" || !strings.HasPrefix(line[len(match[1]):], ": ") {
+			return TrailerOther, false
+		}
+	}
+	return kind, true
+}
+
+func (m *Message) parseBody(lines []string, firstLine int) {
+	start, end := 0, len(lines)
+	for start < end && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	for end > start && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	if start == end {
+		return
+	}
+	m.Body = strings.Join(lines[start:end], "\n")
+	m.BodyPos = Position{Line: firstLine + start, Column: 1}
+}
+
+func (m *Message) parseFooter(lines []string, firstLine int) {
+	// current is the index of the trailer whose value continues on the
+	// next line, if any.
+	current := -1
+	for i, line := range lines {
+		pos := Position{Line: firstLine + i, Column: 1}
+		if strings.TrimSpace(line) == "" {
+			current = -1
+			continue
+		}
+		kind, ok := trailerKind(line)
+		switch {
+		case ok && kind == TrailerCherryPick:
+			match := cherryPickRegexp.FindStringSubmatchIndex(line)
+			m.addTrailer(Trailer{
+				Kind:     kind,
+				Token:    "cherry picked from commit",
+				Value:    line[match[2]:match[3]],
+				Pos:      pos,
+				ValuePos: Position{Line: pos.Line, Column: match[2] + 1},
+			})
+			current = -1
+		case ok:
+			match := trailerRegexp.FindStringSubmatchIndex(line)
+			t := Trailer{
+				Kind:     kind,
+				Token:    line[match[2]:match[3]],
+				Value:    strings.TrimRight(line[match[6]:match[7]], " \t"),
+				Pos:      pos,
+				ValuePos: Position{Line: pos.Line, Column: match[6] + 1},
+			}
+			if match[4] >= 0 {
+				t.Type = line[match[4]:match[5]]
+			}
+			m.addTrailer(t)
+			current = len(m.Trailers) - 1
+		case current >= 0:
+			// Continuation of a multi-line value.
+			m.Trailers[current].Value += "\n" + strings.TrimSpace(line)
+		default:
+			// Free text in the footer, keep it as an unnamed trailer so
+			// that nothing is silently lost.
+			m.addTrailer(Trailer{
+				Kind:     TrailerOther,
+				Value:    line,
+				Pos:      pos,
+				ValuePos: pos,
+			})
+		}
+	}
+}
+
+func (m *Message) addTrailer(t Trailer) {
+	if len(m.Trailers) == 0 {
+		m.FooterPos = t.Pos
+	}
+	m.Trailers = append(m.Trailers, t)
+}
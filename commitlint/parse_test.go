@@ -0,0 +1,169 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package commitlint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	msg, err := Parse(`feat(api)!: remove legacy /devices/list endpoint
+
+The endpoint has been deprecated for two releases.
+Clients should use the new one.
+
+BREAKING CHANGE: Clients must migrate to /devices/v2/list; the legacy
+endpoint now returns 404.
+
+Changelog(fix): Removed the endpoint.
+Ticket: MEN-9420
+Deprecation: The v1 API will be removed.
+Cancel-changelog: 0123abcd
+Co-authored-by: John Doe <john@example.com>
+Signed-off-by: Jane Developer <jane.developer@northern.tech>
+(cherry picked from commit 89abcdef)
+`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "feat", msg.Type)
+	assert.Equal(t, Position{1, 1}, msg.TypePos)
+	assert.Equal(t, "api", msg.Scope)
+	assert.Equal(t, Position{1, 6}, msg.ScopePos)
+	assert.True(t, msg.Breaking)
+	assert.Equal(t, Position{1, 10}, msg.BreakingPos)
+	assert.Equal(t, "remove legacy /devices/list endpoint", msg.Subject)
+	assert.Equal(t, Position{1, 13}, msg.SubjectPos)
+
+	assert.Equal(t, "The endpoint has been deprecated for two releases.\n"+
+		"Clients should use the new one.", msg.Body)
+	assert.Equal(t, Position{3, 1}, msg.BodyPos)
+	assert.Equal(t, Position{6, 1}, msg.FooterPos)
+
+	assert.Equal(t, []Trailer{
+		{
+			Kind:     TrailerBreakingChange,
+			Token:    "BREAKING CHANGE",
+			Value:    "Clients must migrate to /devices/v2/list; the legacy\nendpoint now returns 404.",
+			Pos:      Position{6, 1},
+			ValuePos: Position{6, 18},
+		},
+		{
+			Kind:     TrailerChangelog,
+			Token:    "Changelog",
+			Type:     "fix",
+			Value:    "Removed the endpoint.",
+			Pos:      Position{9, 1},
+			ValuePos: Position{9, 17},
+		},
+		{
+			Kind:     TrailerTicket,
+			Token:    "Ticket",
+			Value:    "MEN-9420",
+			Pos:      Position{10, 1},
+			ValuePos: Position{10, 9},
+		},
+		{
+			Kind:     TrailerDeprecation,
+			Token:    "Deprecation",
+			Value:    "The v1 API will be removed.",
+			Pos:      Position{11, 1},
+			ValuePos: Position{11, 14},
+		},
+		{
+			Kind:     TrailerCancelChangelog,
+			Token:    "Cancel-changelog",
+			Value:    "0123abcd",
+			Pos:      Position{12, 1},
+			ValuePos: Position{12, 19},
+		},
+		{
+			Kind:     TrailerCoAuthoredBy,
+			Token:    "Co-authored-by",
+			Value:    "John Doe <john@example.com>",
+			Pos:      Position{13, 1},
+			ValuePos: Position{13, 17},
+		},
+		{
+			Kind:     TrailerSignedOffBy,
+			Token:    "Signed-off-by",
+			Value:    "Jane Developer <jane.developer@northern.tech>",
+			Pos:      Position{14, 1},
+			ValuePos: Position{14, 16},
+		},
+		{
+			Kind:     TrailerCherryPick,
+			Token:    "cherry picked from commit",
+			Value:    "89abcdef",
+			Pos:      Position{15, 1},
+			ValuePos: Position{15, 28},
+		},
+	}, msg.Trailers)
+	assert.True(t, msg.IsBreaking())
+
+	ticket, ok := msg.Trailer(TrailerTicket)
+	assert.True(t, ok)
+	assert.Equal(t, "MEN-9420", ticket.Value)
+	assert.Len(t, msg.TrailersOf(TrailerSignedOffBy), 1)
+}
+
+func TestParseMinimal(t *testing.T) {
+	msg, err := Parse("chore: foobar")
+	require.NoError(t, err)
+	assert.Equal(t, "chore", msg.Type)
+	assert.Empty(t, msg.Scope)
+	assert.False(t, msg.IsBreaking())
+	assert.Equal(t, "foobar", msg.Subject)
+	assert.Empty(t, msg.Body)
+	assert.Empty(t, msg.Trailers)
+	_, ok := msg.Trailer(TrailerChangelog)
+	assert.False(t, ok)
+}
+
+func TestParseBodyAndFooter(t *testing.T) {
+	// Unknown "Token: value" lines in the body don't start the footer, but
+	// are trailers once in it.
+	msg, err := Parse(`fix: handle restarts
+
+Note: this only happens when dbus restarts.
+
+Changelog: None
+git-subtree-dir: foo
+Ticket: None
+`)
+	require.NoError(t, err)
+	assert.Equal(t, "Note: this only happens when dbus restarts.", msg.Body)
+	require.Len(t, msg.Trailers, 3)
+	assert.Equal(t, TrailerOther, msg.Trailers[1].Kind)
+	assert.Equal(t, "git-subtree-dir", msg.Trailers[1].Token)
+	assert.Equal(t, "foo", msg.Trailers[1].Value)
+}
+
+func TestParseSyntaxError(t *testing.T) {
+	msg, err := Parse("Fix the thing\n\nSigned-off-by: Jane <jane@example.com>\n")
+	var syntaxErr *SyntaxError
+	require.ErrorAs(t, err, &syntaxErr)
+	assert.Equal(t, Position{1, 1}, syntaxErr.Pos)
+	assert.Equal(t, "Fix the thing", msg.Header)
+	// The footer is parsed anyway.
+	assert.Len(t, msg.TrailersOf(TrailerSignedOffBy), 1)
+
+	_, err = Parse("fix(scope):missing space")
+	assert.Error(t, err)
+	_, err = Parse("fix(sco(pe)): nested")
+	assert.Error(t, err)
+}
@@ -290,6 +290,36 @@ Signed-off-by: Kristian Amlie <kristian.amlie@northern.tech>
 (cherry picked from commit 9ce8090ec2e4c7dc4a6ad428751a761254520106)
 "
 
+assert "false" \
+       "Changelog keyword with a suffix" \
+       "fix(client): foobar
+
+Changelog: Nonesense
+Ticket: None
+
+Signed-off-by: Kristian Amlie <kristian.amlie@northern.tech>
+"
+
+assert "false" \
+       "Changelog keyword followed by text" \
+       "fix(client): foobar
+
+Changelog: Title foo
+Ticket: None
+
+Signed-off-by: Kristian Amlie <kristian.amlie@northern.tech>
+"
+
+assert "true" \
+       "Changelog keyword with trailing whitespace" \
+       "fix(client): foobar
+
+Changelog: None  
+Ticket: None
+
+Signed-off-by: Kristian Amlie <kristian.amlie@northern.tech>
+"
+
 assert "true" \
        "Changelog with scope" \
        "fix(client): foobar
@@ -0,0 +1,231 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package commits checks the commits of a git range for sign-offs, the
+// conventional commit schema, and leaks from the hosted or staging branches.
+//
+// It implements the same rules as check_commits.sh, but reports every bad
+// commit instead of stopping at the first.
+package commits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mendersoftware/mendertesting/commitlint"
+	"github.com/mendersoftware/mendertesting/internal/git"
+)
+
+// Rule identifies which commit rule a violation breaks.
+type Rule string
+
+const (
+	// RuleSignoff means that the commit has no Signed-off-by trailer
+	// matching its author.
+	RuleSignoff Rule = "signoff"
+	// RuleSchema means that the commit message doesn't follow the
+	// conventional commit specification.
+	RuleSchema Rule = "conventional-commit"
+	// RuleLeak means that the commit is present in the hosted or staging
+	// branch, and must not be merged anywhere else.
+	RuleLeak Rule = "hosted-leak"
+)
+
+// Violation is a single problem with a single commit.
+type Violation struct {
+	// Commit is the full SHA of the commit.
+	Commit  string
+	Rule    Rule
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("Commit %s: %s", v.Commit, v.Message)
+}
+
+// Linter checks a commit message against the commit schema. It returns a
+// description of every problem found, or an error if the message could not
+// be checked at all.
+type Linter func(ctx context.Context, message string) ([]string, error)
+
+// Policy selects the checks to run.
+type Policy struct {
+	// Signoffs requires a Signed-off-by trailer matching the author.
+	Signoffs bool
+
+	// Schema requires commit messages to pass Lint.
+	Schema bool
+	// Lint is required if Schema is set.
+	Lint Linter
+
+	// TargetBranch is the branch the commits are going to be merged into.
+	// If set, and the remote has a hosted or staging branch, commits
+	// which are only in those branches are rejected.
+	TargetBranch string
+}
+
+// LintWith returns a Linter which checks commit messages against the given
+// commitlint policy, and reports every diagnostic.
+func LintWith(policy commitlint.Policy) Linter {
+	return func(ctx context.Context, message string) ([]string, error) {
+		var problems []string
+		for _, d := range commitlint.Lint(message, policy) {
+			problems = append(problems, fmt.Sprintf("does not adhere to the conventional "+
+				"commit specification: %s", d))
+		}
+		return problems, nil
+	}
+}
+
+// PolicyFromEnv returns the policy check_commits.sh uses when no flags are
+// given: everything is checked, except the schema in repositories with
+// UNVERSIONED_REPOSITORY set. The target branch is only set in CI. Commit
+// messages are linted against grammar.md.
+func PolicyFromEnv() Policy {
+	policy := Policy{
+		Signoffs: true,
+		Schema:   os.Getenv("UNVERSIONED_REPOSITORY") == "",
+		Lint:     LintWith(commitlint.GrammarPolicy()),
+	}
+	if os.Getenv("CI_PIPELINE_ID") != "" {
+		policy.TargetBranch = os.Getenv("CI_EXTERNAL_PULL_REQUEST_TARGET_BRANCH_NAME")
+		if policy.TargetBranch == "" {
+			policy.TargetBranch = "master"
+		}
+	}
+	return policy
+}
+
+var (
+	subtreeRegexp    = regexp.MustCompile(`(?m)^git-subtree-[^:]+:`)
+	dependabotRegexp = regexp.MustCompile(`^dependabot(-preview)?\[bot\] ` +
+		`<[0-9]+\+dependabot(-preview)?\[bot\]@users.noreply.github.com>$`)
+)
+
+// Check checks all non-merge commits in rangeSpec, which is given to
+// git rev-list after splitting it on white space, e.g. "master..HEAD" or
+// "pr_1 --not refs/heads/master". The error is only non-nil if the check
+// itself could not be carried out.
+func Check(ctx context.Context, repoDir, rangeSpec string, policy Policy) ([]Violation, error) {
+	if policy.Schema && policy.Lint == nil {
+		return nil, errors.New("the schema check requires a Lint function")
+	}
+	args := append([]string{"rev-list", "--no-merges"}, strings.Fields(rangeSpec)...)
+	shas, err := git.Lines(ctx, repoDir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	leaked, err := leakedCommits(ctx, repoDir, policy.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	var violations []Violation
+	for _, sha := range shas {
+		more, err := checkCommit(ctx, repoDir, sha, policy)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, more...)
+		if leaked[sha] {
+			violations = append(violations, Violation{
+				Commit: sha,
+				Rule:   RuleLeak,
+				Message: "is present in the hosted or staging branch. " +
+					"Please do not merge this code to another branch (pretty please).",
+			})
+		}
+	}
+	return violations, nil
+}
+
+func checkCommit(ctx context.Context, repoDir, sha string, policy Policy) ([]Violation, error) {
+	message, err := git.Output(ctx, repoDir, "show", "-s", "--format=%B", sha)
+	if err != nil {
+		return nil, err
+	}
+	var violations []Violation
+
+	if policy.Schema {
+		problems, err := policy.Lint(ctx, message)
+		if err != nil {
+			return nil, err
+		}
+		for _, problem := range problems {
+			violations = append(violations, Violation{
+				Commit:  sha,
+				Rule:    RuleSchema,
+				Message: problem,
+			})
+		}
+	}
+
+	// Ignore commits that have git-subtree tags in them. They are a PITA
+	// both to sign and add changelogs to, and signing should anyway be
+	// present in the original repository.
+	if !policy.Signoffs || subtreeRegexp.MatchString(message) {
+		return violations, nil
+	}
+	author, err := git.Output(ctx, repoDir, "show", "-s", "--format=%an <%ae>", sha)
+	if err != nil {
+		return nil, err
+	}
+	author = strings.TrimSpace(author)
+	// Dependabot's Git user and Signed-off-by user differ.
+	if dependabotRegexp.MatchString(author) {
+		return violations, nil
+	}
+	if !strings.Contains(message, "Signed-off-by: "+author) {
+		violations = append(violations, Violation{
+			Commit: sha,
+			Rule:   RuleSignoff,
+			Message: "is not signed off! Use --signoff with your commit. Make sure that " +
+				"the Author of the commit matches the one in Signed-off-by",
+		})
+	}
+	return violations, nil
+}
+
+// leakedCommits returns the commits which are in origin/hosted, or else
+// origin/staging, but not in the target branch. Without origin/<target>
+// there is nothing to compare with, and nothing is reported.
+func leakedCommits(ctx context.Context, repoDir, target string) (map[string]bool, error) {
+	leaked := map[string]bool{}
+	if target == "" || target == "hosted" || target == "staging" {
+		return leaked, nil
+	}
+	// Shallow and detached clones may not have the target branch.
+	if !git.Succeeds(ctx, repoDir, "rev-parse", "--verify", "--quiet", "origin/"+target) {
+		return leaked, nil
+	}
+	for _, branch := range []string{"origin/hosted", "origin/staging"} {
+		if !git.Succeeds(ctx, repoDir, "rev-parse", "--verify", "--quiet", branch) {
+			continue
+		}
+		shas, err := git.Lines(ctx, repoDir, "rev-list", "origin/"+target+".."+branch)
+		if err != nil {
+			return nil, err
+		}
+		for _, sha := range shas {
+			leaked[sha] = true
+		}
+		break
+	}
+	return leaked, nil
+}
@@ -0,0 +1,237 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package commits
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mendersoftware/mendertesting/commitlint"
+)
+
+const author = "Test Testison <test@test.com>"
+
+type testRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	r := &testRepo{t: t, dir: t.TempDir()}
+	r.git("init", "-q", "-b", "master")
+	return r
+}
+
+func (r *testRepo) gitAs(name, email string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+name,
+		"GIT_AUTHOR_EMAIL="+email,
+		"GIT_COMMITTER_NAME=Test Testison",
+		"GIT_COMMITTER_EMAIL=test@test.com",
+	)
+	output, err := cmd.CombinedOutput()
+	require.NoError(r.t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+func (r *testRepo) git(args ...string) string {
+	return r.gitAs("Test Testison", "test@test.com", args...)
+}
+
+func (r *testRepo) commit(msg string) string {
+	r.git("commit", "-q", "--allow-empty", "-m", msg)
+	return r.git("rev-parse", "HEAD")
+}
+
+func violations(v []Violation) map[string][]Rule {
+	result := map[string][]Rule{}
+	for _, violation := range v {
+		result[violation.Commit] = append(result[violation.Commit], violation.Rule)
+	}
+	return result
+}
+
+// lintStub rejects messages starting with "bad".
+func lintStub(ctx context.Context, message string) ([]string, error) {
+	if strings.HasPrefix(message, "bad") {
+		return []string{"bad type", "bad subject"}, nil
+	}
+	return nil, nil
+}
+
+func TestCheck(t *testing.T) {
+	r := newTestRepo(t)
+	base := r.commit("chore: initial")
+	good := r.commit("chore: good\n\nSigned-off-by: " + author)
+	unsigned := r.commit("chore: unsigned")
+	badSchema := r.commit("bad: schema\n\nSigned-off-by: " + author)
+	subtree := r.commit("chore: subtree\n\ngit-subtree-dir: foo\ngit-subtree-split: 1234")
+	r.gitAs("dependabot[bot]", "49699333+dependabot[bot]@users.noreply.github.com",
+		"commit", "-q", "--allow-empty", "-m", "chore: bump")
+	dependabot := r.git("rev-parse", "HEAD")
+	r.gitAs("Other Person", "other@test.com",
+		"commit", "-q", "--allow-empty", "-m", "chore: other\n\nSigned-off-by: "+author)
+	otherAuthor := r.git("rev-parse", "HEAD")
+
+	policy := Policy{Signoffs: true, Schema: true, Lint: lintStub}
+	result, err := Check(context.Background(), r.dir, base+"..HEAD", policy)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]Rule{
+		unsigned:    {RuleSignoff},
+		badSchema:   {RuleSchema, RuleSchema},
+		otherAuthor: {RuleSignoff},
+	}, violations(result))
+	assert.NotContains(t, violations(result), good)
+	assert.NotContains(t, violations(result), subtree)
+	assert.NotContains(t, violations(result), dependabot)
+
+	// Only sign-offs.
+	result, err = Check(context.Background(), r.dir, base+"..HEAD", Policy{Signoffs: true})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]Rule{
+		unsigned:    {RuleSignoff},
+		otherAuthor: {RuleSignoff},
+	}, violations(result))
+
+	_, err = Check(context.Background(), r.dir, base+"..HEAD", Policy{Schema: true})
+	assert.Error(t, err)
+	_, err = Check(context.Background(), r.dir, "nonexistent..HEAD", Policy{})
+	assert.Error(t, err)
+}
+
+func TestCheckLeaks(t *testing.T) {
+	r := newTestRepo(t)
+	master := r.commit("chore: initial\n\nSigned-off-by: " + author)
+	r.git("update-ref", "refs/remotes/origin/master", master)
+	r.git("checkout", "-q", "-b", "hosted")
+	leaked := r.commit("chore: hosted only\n\nSigned-off-by: " + author)
+	r.git("update-ref", "refs/remotes/origin/hosted", leaked)
+	r.git("checkout", "-q", "-b", "feature")
+	feature := r.commit("chore: feature\n\nSigned-off-by: " + author)
+
+	policy := Policy{TargetBranch: "master"}
+	result, err := Check(context.Background(), r.dir, master+"..HEAD", policy)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]Rule{leaked: {RuleLeak}}, violations(result))
+	assert.NotContains(t, violations(result), feature)
+
+	// Merging into hosted itself is fine.
+	policy.TargetBranch = "hosted"
+	result, err = Check(context.Background(), r.dir, master+"..HEAD", policy)
+	require.NoError(t, err)
+	assert.Empty(t, result)
+
+	// A clone without the target branch can't tell, and doesn't fail.
+	policy.TargetBranch = "feature"
+	result, err = Check(context.Background(), r.dir, master+"..HEAD", policy)
+	require.NoError(t, err)
+	assert.Empty(t, result)
+}
+
+func TestLintWith(t *testing.T) {
+	lint := LintWith(commitlint.GrammarPolicy())
+	problems, err := lint(context.Background(), "refactor!: drop the old API\n\n"+
+		"Signed-off-by: "+author)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+
+	problems, err = lint(context.Background(), "refac: tidy up.\nbody\n")
+	require.NoError(t, err)
+	require.Len(t, problems, 3)
+	assert.Contains(t, problems[0], `1:1: type "refac" is not one of`)
+	assert.Contains(t, problems[0], `did you mean "refactor"?`)
+	assert.Contains(t, problems[1], "[subject-full-stop]")
+	assert.Contains(t, problems[2], "[header-blank-line]")
+
+	problems, err = LintWith(commitlint.LegacyPolicy())(context.Background(), "refac: tidy up\n")
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
+
+func setenv(t *testing.T, name, value string) {
+	old, wasSet := os.LookupEnv(name)
+	if value == "" {
+		require.NoError(t, os.Unsetenv(name))
+	} else {
+		require.NoError(t, os.Setenv(name, value))
+	}
+	t.Cleanup(func() {
+		if wasSet {
+			os.Setenv(name, old)
+		} else {
+			os.Unsetenv(name)
+		}
+	})
+}
+
+func TestRangeFromEnv(t *testing.T) {
+	r := newTestRepo(t)
+	master := r.commit("chore: initial")
+	r.git("checkout", "-q", "-b", "pr_123")
+	r.commit("chore: pull request")
+	r.git("branch", "other", master)
+
+	setenv(t, "COMMIT_RANGE", "")
+	setenv(t, "CI_COMMIT_REF_NAME", "")
+	setenv(t, "TRAVIS_BRANCH", "")
+
+	commitRange, err := RangeFromEnv(context.Background(), r.dir)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRange, commitRange)
+
+	setenv(t, "TRAVIS_BRANCH", "master")
+	commitRange, err = RangeFromEnv(context.Background(), r.dir)
+	require.NoError(t, err)
+	assert.Equal(t, "master..HEAD", commitRange)
+
+	setenv(t, "CI_COMMIT_REF_NAME", "pr_123")
+	commitRange, err = RangeFromEnv(context.Background(), r.dir)
+	require.NoError(t, err)
+	assert.Equal(t, "pr_123 --not refs/heads/master refs/heads/other", commitRange)
+
+	setenv(t, "COMMIT_RANGE", "a..b")
+	commitRange, err = RangeFromEnv(context.Background(), r.dir)
+	require.NoError(t, err)
+	assert.Equal(t, "a..b", commitRange)
+}
+
+func TestPolicyFromEnv(t *testing.T) {
+	setenv(t, "UNVERSIONED_REPOSITORY", "")
+	setenv(t, "CI_PIPELINE_ID", "")
+	setenv(t, "CI_EXTERNAL_PULL_REQUEST_TARGET_BRANCH_NAME", "")
+	policy := PolicyFromEnv()
+	assert.True(t, policy.Signoffs)
+	assert.True(t, policy.Schema)
+	assert.NotNil(t, policy.Lint)
+	assert.Empty(t, policy.TargetBranch)
+
+	setenv(t, "UNVERSIONED_REPOSITORY", "true")
+	setenv(t, "CI_PIPELINE_ID", "1234")
+	policy = PolicyFromEnv()
+	assert.True(t, policy.Signoffs)
+	assert.False(t, policy.Schema)
+	assert.Equal(t, "master", policy.TargetBranch)
+
+	setenv(t, "CI_EXTERNAL_PULL_REQUEST_TARGET_BRANCH_NAME", "3.7.x")
+	assert.Equal(t, "3.7.x", PolicyFromEnv().TargetBranch)
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package commits
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mendersoftware/mendertesting/internal/git"
+)
+
+// DefaultRange is checked if nothing else is specified: the previous commit.
+const DefaultRange = "HEAD~1..HEAD"
+
+var pullRequestRefRegexp = regexp.MustCompile(`^pr_[0-9]`)
+
+// RangeFromEnv detects the range to check from the CI environment, like
+// check_commits.sh: COMMIT_RANGE if set, a GitHub pull request branch
+// mirrored to GitLab, TRAVIS_BRANCH, or else DefaultRange.
+func RangeFromEnv(ctx context.Context, repoDir string) (string, error) {
+	if commitRange := os.Getenv("COMMIT_RANGE"); commitRange != "" {
+		return commitRange, nil
+	}
+
+	// Gitlab unfortunately doesn't record base branches of commits when
+	// the PR comes from Github, so we need to detect branch names of PRs
+	// manually, and then reconstruct the correct range from that, by
+	// excluding all other branches.
+	if ref := os.Getenv("CI_COMMIT_REF_NAME"); pullRequestRefRegexp.MatchString(ref) {
+		all, err := git.Lines(ctx, repoDir, "for-each-ref", "--format=%(refname)")
+		if err != nil {
+			return "", err
+		}
+		pointsAt, err := git.Lines(ctx, repoDir, "for-each-ref",
+			"--format=%(refname)", "--points-at", ref)
+		if err != nil {
+			return "", err
+		}
+		skip := map[string]bool{}
+		for _, r := range pointsAt {
+			skip[r] = true
+		}
+		var exclude []string
+		for _, r := range all {
+			if !skip[r] {
+				exclude = append(exclude, r)
+			}
+		}
+		return ref + " --not " + strings.Join(exclude, " "), nil
+	}
+
+	if branch := os.Getenv("TRAVIS_BRANCH"); branch != "" {
+		return branch + "..HEAD", nil
+	}
+	return DefaultRange, nil
+}
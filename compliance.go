@@ -0,0 +1,64 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mendertesting
+
+import (
+	"testing"
+
+	"github.com/mendersoftware/mendertesting/config"
+)
+
+// CheckMenderCompliance checks the current working directory using the
+// options set through SetLicenseFileForDependency and
+// SetFirstEnterpriseCommit.
+func CheckMenderCompliance(t *testing.T) {
+	CheckMenderComplianceWithOptions(t, DefaultOptions())
+}
+
+// CheckMenderComplianceWithOptions checks a repository using the given
+// options. It does not touch any package level state, so it is safe to use
+// from parallel tests. Every check runs as its own subtest, named after the
+// Check, e.g. "Checking Mender compliance/headers", and a failing check
+// doesn't stop the others.
+//
+// Checks which are not enabled in .mendertesting.yaml are skipped.
+func CheckMenderComplianceWithOptions(t *testing.T, opts Options) {
+	t.Run("Checking Mender compliance", func(t *testing.T) {
+		cfg, err := loadConfig(opts)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if cfg.Debug {
+			t.Logf("Configuration: %+v", *cfg)
+		}
+		if _, err := enabledChecks(cfg); err != nil {
+			t.Error(err)
+			return
+		}
+		for _, check := range complianceChecks {
+			run := check.run
+			enabled := cfg.Enabled(string(check.check))
+			t.Run(string(check.check), func(t *testing.T) {
+				if !enabled {
+					t.Skipf("not enabled in %s", config.FileName)
+				}
+				if err := run(cfg, opts); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,417 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package config reads the compliance policy of a repository from the
+// .mendertesting.yaml file at its root:
+//
+//	checks: [headers, commits, licenses, license-year]
+//	first_enterprise_commit: 0123456789abcdef0123456789abcdef01234567
+//	copyright_holder: Northern.tech AS
+//	debug: false
+//	headers:
+//	  ignore_regexp: '\./exclude-this/.*\.py'
+//	  file_types: [ruby, yaml]
+//	  templates:
+//	    - dir: third_party/contrib
+//	      open_source: .mendertesting/contrib-header.txt
+//	      copyright_holder: Contributor Ltd.
+//	  styles: [spdx, full]
+//	  spdx:
+//	    open_source: Apache-2.0
+//	    enterprise: LicenseRef-Northern.tech-Enterprise
+//	  generated:
+//	    rule: check
+//	    markers: ['^# Autogenerated by Thrift']
+//	    open_source: .mendertesting/generator-header.txt
+//	licenses:
+//	  known_license_files: [path/to/README.md]
+//	  open_source:
+//	    deny: [AGPL-*]
+//	  enterprise:
+//	    deny: [GPL-*, AGPL-*]
+//	commits:
+//	  unversioned: false
+//	  legacy: false
+//	  types: [feat, fix, chore]
+//	  scopes: [client, server]
+//
+// The environment variables used by the CI scripts override the file, see
+// ApplyEnv.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mendersoftware/mendertesting/commitlint"
+	"github.com/mendersoftware/mendertesting/headers"
+	"github.com/mendersoftware/mendertesting/licenses"
+)
+
+// FileName is the name of the policy file, at the root of the repository.
+const FileName = ".mendertesting.yaml"
+
+// Config is the compliance policy of a repository.
+type Config struct {
+	// Checks are the names of the enabled checks. All checks are enabled
+	// if empty.
+	Checks []string `yaml:"checks,omitempty"`
+	// FirstEnterpriseCommit is the very first commit after the fork point
+	// on the Enterprise branch, if this is an Enterprise repository.
+	FirstEnterpriseCommit string `yaml:"first_enterprise_commit,omitempty"`
+	// CopyrightHolder is the holder in the copyright lines. Each check has
+	// its own default.
+	CopyrightHolder string `yaml:"copyright_holder,omitempty"`
+	// Debug traces the scripts.
+	Debug bool `yaml:"debug,omitempty"`
+
+	Headers  Headers  `yaml:"headers,omitempty"`
+	Licenses Licenses `yaml:"licenses,omitempty"`
+	Commits  Commits  `yaml:"commits,omitempty"`
+}
+
+// Headers is the policy of the source header check.
+type Headers struct {
+	// IgnoreRegexp is a find(1) regular expression of files which are not
+	// checked.
+	IgnoreRegexp string `yaml:"ignore_regexp,omitempty"`
+	// FileTypes are the file types to check besides the default ones.
+	FileTypes []string `yaml:"file_types,omitempty"`
+	// Templates select other header templates and copyright holders for
+	// the files below some directories.
+	Templates []HeaderTemplate `yaml:"templates,omitempty"`
+	// Styles are the accepted header styles, "full" and "spdx". The fixer
+	// writes the first one.
+	Styles []string `yaml:"styles,omitempty"`
+	// SPDX are the license identifiers of SPDX headers.
+	SPDX SPDX `yaml:"spdx,omitempty"`
+	// Generated is the policy of generated files.
+	Generated Generated `yaml:"generated,omitempty"`
+}
+
+// Generated is the policy of generated files.
+type Generated struct {
+	// Rule is "skip", the default, or "check".
+	Rule string `yaml:"rule,omitempty"`
+	// Markers are regular expressions of lines which mark generated files,
+	// besides "Code generated ... DO NOT EDIT." and "@generated" comments.
+	Markers []string `yaml:"markers,omitempty"`
+	// OpenSource and Enterprise are header template files of generated
+	// files, relative to the root of the repository.
+	OpenSource string `yaml:"open_source,omitempty"`
+	Enterprise string `yaml:"enterprise,omitempty"`
+}
+
+// SPDX are the license identifiers of the license classes in SPDX headers.
+type SPDX struct {
+	OpenSource string `yaml:"open_source,omitempty"`
+	Enterprise string `yaml:"enterprise,omitempty"`
+}
+
+// HeaderTemplate selects the header templates and the copyright holder of
+// the files below a directory. The templates are files, relative to the
+// root of the repository, in the format of headers.ParseTemplate.
+type HeaderTemplate struct {
+	Dir             string `yaml:"dir,omitempty"`
+	OpenSource      string `yaml:"open_source,omitempty"`
+	Enterprise      string `yaml:"enterprise,omitempty"`
+	CopyrightHolder string `yaml:"copyright_holder,omitempty"`
+}
+
+// Licenses is the policy of the dependency license check.
+type Licenses struct {
+	// KnownLicenseFiles are license files of dependencies which don't
+	// follow the common license file names.
+	KnownLicenseFiles []string `yaml:"known_license_files,omitempty"`
+	// OpenSource and Enterprise are the license policies of the Open
+	// Source and the Enterprise repositories.
+	OpenSource LicensePolicy `yaml:"open_source,omitempty"`
+	Enterprise LicensePolicy `yaml:"enterprise,omitempty"`
+}
+
+// LicensePolicy allows and denies the licenses of dependencies by their SPDX
+// license identifiers, or patterns like "GPL-*". See licenses.Policy.
+type LicensePolicy struct {
+	Allow []string `yaml:"allow,omitempty"`
+	Deny  []string `yaml:"deny,omitempty"`
+}
+
+// Commits is the policy of the commit check.
+type Commits struct {
+	// Unversioned repositories don't check the commit message schema.
+	Unversioned bool `yaml:"unversioned,omitempty"`
+	// Legacy lints commit messages with the strict legacy rules.
+	Legacy bool `yaml:"legacy,omitempty"`
+	// Types replaces the allowed commit types.
+	Types []string `yaml:"types,omitempty"`
+	// Scopes are the allowed scopes. Any scope is allowed if empty.
+	Scopes []string `yaml:"scopes,omitempty"`
+}
+
+// Read reads the policy file in the root of the repository. Without a
+// policy file the configuration is empty. Unknown keys are an error, to
+// catch typos.
+func Read(root string) (*Config, error) {
+	name := filepath.Join(root, FileName)
+	content, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return cfg, nil
+}
+
+// Load reads the policy file in the root of the repository and applies the
+// environment on top.
+func Load(root string) (*Config, error) {
+	cfg, err := Read(root)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ApplyEnv()
+	return cfg, nil
+}
+
+// ApplyEnv overrides the configuration with the environment variables which
+// are set:
+//
+//   - FIRST_ENT_COMMIT
+//   - COPYRIGHT_HOLDER
+//   - KNOWN_LICENSE_FILES, separated by white space
+//   - LICENSE_HEADERS_IGNORE_FILES_REGEXP
+//   - LICENSE_HEADERS_FILE_TYPES, separated by commas or white space
+//   - LICENSE_HEADERS_STYLES, separated by commas or white space
+//   - LICENSE_HEADERS_GENERATED
+//   - UNVERSIONED_REPOSITORY, if not empty
+//   - COMMITLINT_LEGACY, if not empty
+//   - DEBUG_MENDERTESTING, if not empty
+func (c *Config) ApplyEnv() {
+	if env := os.Getenv("FIRST_ENT_COMMIT"); env != "" {
+		c.FirstEnterpriseCommit = env
+	}
+	if env := os.Getenv("COPYRIGHT_HOLDER"); env != "" {
+		c.CopyrightHolder = env
+	}
+	if env := os.Getenv("KNOWN_LICENSE_FILES"); env != "" {
+		c.Licenses.KnownLicenseFiles = strings.Fields(env)
+	}
+	if env := os.Getenv("LICENSE_HEADERS_IGNORE_FILES_REGEXP"); env != "" {
+		c.Headers.IgnoreRegexp = env
+	}
+	if env := os.Getenv("LICENSE_HEADERS_FILE_TYPES"); env != "" {
+		c.Headers.FileTypes = headers.SplitTypes(env)
+	}
+	if env := os.Getenv("LICENSE_HEADERS_STYLES"); env != "" {
+		c.Headers.Styles = headers.SplitTypes(env)
+	}
+	if env := os.Getenv("LICENSE_HEADERS_GENERATED"); env != "" {
+		c.Headers.Generated.Rule = env
+	}
+	if os.Getenv("UNVERSIONED_REPOSITORY") != "" {
+		c.Commits.Unversioned = true
+	}
+	if os.Getenv("COMMITLINT_LEGACY") != "" {
+		c.Commits.Legacy = true
+	}
+	if os.Getenv("DEBUG_MENDERTESTING") != "" {
+		c.Debug = true
+	}
+}
+
+// Enabled reports whether the named check is enabled.
+func (c *Config) Enabled(check string) bool {
+	if len(c.Checks) == 0 {
+		return true
+	}
+	for _, name := range c.Checks {
+		if name == check {
+			return true
+		}
+	}
+	return false
+}
+
+// HeaderStyles returns the accepted header styles.
+func (c *Config) HeaderStyles() []headers.HeaderStyle {
+	var styles []headers.HeaderStyle
+	for _, style := range c.Headers.Styles {
+		styles = append(styles, headers.HeaderStyle(style))
+	}
+	return styles
+}
+
+// HeadersChecker returns the header checker of the repository at root.
+func (c *Config) HeadersChecker(root string) (*headers.Checker, error) {
+	templates, err := c.HeaderTemplates(root)
+	if err != nil {
+		return nil, err
+	}
+	checker := &headers.Checker{
+		Root:                  root,
+		FirstEnterpriseCommit: c.FirstEnterpriseCommit,
+		Types:                 c.Headers.FileTypes,
+		CopyrightHolder:       c.CopyrightHolder,
+		Templates:             templates,
+		Styles:                c.HeaderStyles(),
+		SPDXOpenSource:        c.Headers.SPDX.OpenSource,
+		SPDXEnterprise:        c.Headers.SPDX.Enterprise,
+		Generated:             headers.GeneratedRule(c.Headers.Generated.Rule),
+		PolicyFiles:           c.PolicyFiles(),
+	}
+	if c.Headers.IgnoreRegexp != "" {
+		re, err := headers.CompileFindRegexp(c.Headers.IgnoreRegexp)
+		if err != nil {
+			return nil, err
+		}
+		checker.Ignore = append(checker.Ignore, re)
+	}
+	for _, marker := range c.Headers.Generated.Markers {
+		re, err := regexp.Compile(marker)
+		if err != nil {
+			return nil, fmt.Errorf("%s: generated file marker: %w", FileName, err)
+		}
+		checker.GeneratedMarkers = append(checker.GeneratedMarkers, re)
+	}
+	if checker.GeneratedOpenSource, err = readTemplate(root,
+		c.Headers.Generated.OpenSource); err != nil {
+		return nil, err
+	}
+	if checker.GeneratedEnterprise, err = readTemplate(root,
+		c.Headers.Generated.Enterprise); err != nil {
+		return nil, err
+	}
+	return checker, nil
+}
+
+// PolicyFiles returns the slash separated paths, relative to the root of the
+// repository, of the configuration file and the header templates it refers
+// to. A change to any of them requires a full header check.
+func (c *Config) PolicyFiles() []string {
+	files := []string{FileName}
+	for _, t := range c.Headers.Templates {
+		files = appendNonEmpty(files, t.OpenSource, t.Enterprise)
+	}
+	return appendNonEmpty(files, c.Headers.Generated.OpenSource, c.Headers.Generated.Enterprise)
+}
+
+func appendNonEmpty(list []string, values ...string) []string {
+	for _, value := range values {
+		if value != "" {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+// readTemplate reads a header template file relative to root, if any.
+func readTemplate(root, name string) (*headers.Template, error) {
+	if name == "" {
+		return nil, nil
+	}
+	return headers.ReadTemplate(filepath.Join(root, filepath.FromSlash(name)))
+}
+
+// HeaderTemplates reads the header templates of the repository at root.
+func (c *Config) HeaderTemplates(root string) ([]headers.DirTemplate, error) {
+	var templates []headers.DirTemplate
+	for _, t := range c.Headers.Templates {
+		openSource, err := readTemplate(root, t.OpenSource)
+		if err != nil {
+			return nil, err
+		}
+		enterprise, err := readTemplate(root, t.Enterprise)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, headers.DirTemplate{
+			Dir:             t.Dir,
+			OpenSource:      openSource,
+			Enterprise:      enterprise,
+			CopyrightHolder: t.CopyrightHolder,
+		})
+	}
+	return templates, nil
+}
+
+// CommitlintPolicy returns the commit message policy.
+func (c *Config) CommitlintPolicy() commitlint.Policy {
+	policy := commitlint.GrammarPolicy()
+	if c.Commits.Legacy {
+		policy = commitlint.LegacyPolicy()
+	}
+	if len(c.Commits.Types) > 0 {
+		policy.Types = c.Commits.Types
+	}
+	policy.Scopes = c.Commits.Scopes
+	return policy
+}
+
+// LicensePolicy returns the license policy of dependencies: the Enterprise
+// one if this is an Enterprise repository, or else the Open Source one.
+func (c *Config) LicensePolicy() licenses.Policy {
+	policy := c.Licenses.OpenSource
+	if c.FirstEnterpriseCommit != "" {
+		policy = c.Licenses.Enterprise
+	}
+	return licenses.Policy{Allow: policy.Allow, Deny: policy.Deny}
+}
+
+// Shell returns the configuration as the environment variables read by the
+// CI scripts, in a form which can be evaluated by the shell. Only variables
+// with a value are set, and MENDERTESTING_CHECKS has the enabled checks.
+func (c *Config) Shell() string {
+	var b strings.Builder
+	set := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s=%s\n", name, shellQuote(value))
+		}
+	}
+	flag := func(name string, value bool) {
+		if value {
+			set(name, "true")
+		}
+	}
+	set("MENDERTESTING_CHECKS", strings.Join(c.Checks, " "))
+	set("FIRST_ENT_COMMIT", c.FirstEnterpriseCommit)
+	set("COPYRIGHT_HOLDER", c.CopyrightHolder)
+	set("KNOWN_LICENSE_FILES", strings.Join(c.Licenses.KnownLicenseFiles, " "))
+	set("LICENSE_HEADERS_IGNORE_FILES_REGEXP", c.Headers.IgnoreRegexp)
+	set("LICENSE_HEADERS_FILE_TYPES", strings.Join(c.Headers.FileTypes, ","))
+	set("LICENSE_HEADERS_STYLES", strings.Join(c.Headers.Styles, ","))
+	set("LICENSE_HEADERS_GENERATED", c.Headers.Generated.Rule)
+	flag("UNVERSIONED_REPOSITORY", c.Commits.Unversioned)
+	flag("COMMITLINT_LEGACY", c.Commits.Legacy)
+	flag("DEBUG_MENDERTESTING", c.Debug)
+	return b.String()
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,227 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mendersoftware/mendertesting/headers"
+	"github.com/mendersoftware/mendertesting/licenses"
+)
+
+const sample = `checks: [headers, commits]
+first_enterprise_commit: 0123456789abcdef
+copyright_holder: Acme Inc.
+headers:
+  ignore_regexp: '\./exclude-this/.*\.py'
+  file_types: [ruby, yaml]
+  styles: [spdx, full]
+  spdx:
+    enterprise: LicenseRef-Acme-Proprietary
+licenses:
+  known_license_files: [vendor/example.com/lib/README.md]
+  open_source:
+    deny: [AGPL-*]
+  enterprise:
+    allow: [MIT, BSD-*, Apache-2.0]
+commits:
+  types: [feat, fix]
+  scopes: [client, server]
+`
+
+// clearEnv unsets the environment variables which override the file.
+func clearEnv(t *testing.T) {
+	for _, name := range []string{
+		"FIRST_ENT_COMMIT",
+		"COPYRIGHT_HOLDER",
+		"KNOWN_LICENSE_FILES",
+		"LICENSE_HEADERS_IGNORE_FILES_REGEXP",
+		"LICENSE_HEADERS_FILE_TYPES",
+		"LICENSE_HEADERS_STYLES",
+		"UNVERSIONED_REPOSITORY",
+		"COMMITLINT_LEGACY",
+		"DEBUG_MENDERTESTING",
+	} {
+		setenv(t, name, "")
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, FileName), []byte(content), 0644))
+	return dir
+}
+
+func TestRead(t *testing.T) {
+	cfg, err := Read(writeConfig(t, sample))
+	require.NoError(t, err)
+	assert.Equal(t, &Config{
+		Checks:                []string{"headers", "commits"},
+		FirstEnterpriseCommit: "0123456789abcdef",
+		CopyrightHolder:       "Acme Inc.",
+		Headers: Headers{
+			IgnoreRegexp: `\./exclude-this/.*\.py`,
+			FileTypes:    []string{"ruby", "yaml"},
+			Styles:       []string{"spdx", "full"},
+			SPDX:         SPDX{Enterprise: "LicenseRef-Acme-Proprietary"},
+		},
+		Licenses: Licenses{
+			KnownLicenseFiles: []string{"vendor/example.com/lib/README.md"},
+			OpenSource:        LicensePolicy{Deny: []string{"AGPL-*"}},
+			Enterprise:        LicensePolicy{Allow: []string{"MIT", "BSD-*", "Apache-2.0"}},
+		},
+		Commits: Commits{
+			Types:  []string{"feat", "fix"},
+			Scopes: []string{"client", "server"},
+		},
+	}, cfg)
+	assert.True(t, cfg.Enabled("headers"))
+	assert.False(t, cfg.Enabled("licenses"))
+	assert.Equal(t, []headers.HeaderStyle{headers.SPDX, headers.FullText}, cfg.HeaderStyles())
+
+	policy := cfg.CommitlintPolicy()
+	assert.Equal(t, []string{"feat", "fix"}, policy.Types)
+	assert.Equal(t, []string{"client", "server"}, policy.Scopes)
+
+	// The Enterprise license policy applies to Enterprise repositories.
+	assert.Equal(t, licenses.Policy{Allow: []string{"MIT", "BSD-*", "Apache-2.0"}},
+		cfg.LicensePolicy())
+	cfg.FirstEnterpriseCommit = ""
+	assert.Equal(t, licenses.Policy{Deny: []string{"AGPL-*"}}, cfg.LicensePolicy())
+	cfg.FirstEnterpriseCommit = "0123456789abcdef"
+
+	// Without a file, everything is enabled.
+	cfg, err = Read(t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, &Config{}, cfg)
+	assert.True(t, cfg.Enabled("licenses"))
+
+	cfg, err = Read(writeConfig(t, ""))
+	require.NoError(t, err)
+	assert.Equal(t, &Config{}, cfg)
+
+	dir := writeConfig(t, "checks: [headers]\ncopyright: Acme Inc.\n")
+	_, err = Read(dir)
+	assert.ErrorContains(t, err, filepath.Join(dir, FileName)+": yaml: unmarshal errors")
+	assert.ErrorContains(t, err, "field copyright not found")
+}
+
+func TestLoad(t *testing.T) {
+	clearEnv(t)
+	setenv(t, "FIRST_ENT_COMMIT", "fedcba9876543210")
+	setenv(t, "LICENSE_HEADERS_FILE_TYPES", "lua, css")
+	setenv(t, "COMMITLINT_LEGACY", "true")
+
+	cfg, err := Load(writeConfig(t, sample))
+	require.NoError(t, err)
+	assert.Equal(t, "fedcba9876543210", cfg.FirstEnterpriseCommit)
+	assert.Equal(t, []string{"lua", "css"}, cfg.Headers.FileTypes)
+	assert.Equal(t, "Acme Inc.", cfg.CopyrightHolder)
+	assert.True(t, cfg.Commits.Legacy)
+	assert.False(t, cfg.Commits.Unversioned)
+}
+
+func TestShell(t *testing.T) {
+	cfg := &Config{
+		Checks:          []string{"headers", "licenses"},
+		CopyrightHolder: "Acme's Inc.",
+		Headers: Headers{
+			FileTypes: []string{"ruby", "yaml"},
+		},
+		Commits: Commits{
+			Unversioned: true,
+		},
+	}
+	assert.Equal(t, `MENDERTESTING_CHECKS='headers licenses'
+COPYRIGHT_HOLDER='Acme'\''s Inc.'
+LICENSE_HEADERS_FILE_TYPES='ruby,yaml'
+UNVERSIONED_REPOSITORY='true'
+`, cfg.Shell())
+	assert.Empty(t, (&Config{}).Shell())
+}
+
+func TestHeaderTemplates(t *testing.T) {
+	dir := writeConfig(t, `headers:
+  templates:
+    - dir: contrib
+      open_source: templates/contrib.txt
+      copyright_holder: Contributor
+`)
+	cfg, err := Read(dir)
+	require.NoError(t, err)
+	_, err = cfg.HeaderTemplates(dir)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "templates"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "contrib.txt"),
+		[]byte("Copyright {year} {holder}\n\nMIT\n"), 0644))
+	templates, err := cfg.HeaderTemplates(dir)
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	assert.Equal(t, "contrib", templates[0].Dir)
+	assert.Equal(t, "Copyright {year} {holder}\n\nMIT\n", templates[0].OpenSource.String())
+	assert.Nil(t, templates[0].Enterprise)
+	assert.Equal(t, "Contributor", templates[0].CopyrightHolder)
+	assert.Equal(t, []string{FileName, "templates/contrib.txt"}, cfg.PolicyFiles())
+}
+
+func TestHeadersChecker(t *testing.T) {
+	dir := writeConfig(t, `copyright_holder: Acme Inc.
+headers:
+  ignore_regexp: '\./gen/.*'
+  styles: [spdx]
+  generated:
+    rule: check
+    markers: ['^# Autogenerated by Thrift']
+`)
+	cfg, err := Read(dir)
+	require.NoError(t, err)
+	checker, err := cfg.HeadersChecker(dir)
+	require.NoError(t, err)
+	assert.Equal(t, dir, checker.Root)
+	assert.Equal(t, "Acme Inc.", checker.CopyrightHolder)
+	assert.Equal(t, []headers.HeaderStyle{headers.SPDX}, checker.Styles)
+	assert.Equal(t, headers.CheckGenerated, checker.Generated)
+	require.Len(t, checker.Ignore, 1)
+	assert.True(t, checker.Ignore[0].MatchString("./gen/a.go"))
+	require.Len(t, checker.GeneratedMarkers, 1)
+	assert.Nil(t, checker.GeneratedOpenSource)
+	assert.Equal(t, []string{FileName}, checker.PolicyFiles)
+
+	cfg.Headers.Generated.Markers = []string{"(unclosed"}
+	_, err = cfg.HeadersChecker(dir)
+	assert.ErrorContains(t, err, FileName+": generated file marker: error parsing regexp")
+}
+
+func setenv(t *testing.T, name, value string) {
+	old, wasSet := os.LookupEnv(name)
+	if value == "" {
+		require.NoError(t, os.Unsetenv(name))
+	} else {
+		require.NoError(t, os.Setenv(name, value))
+	}
+	t.Cleanup(func() {
+		if wasSet {
+			os.Setenv(name, old)
+		} else {
+			os.Unsetenv(name)
+		}
+	})
+}
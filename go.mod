@@ -1,5 +1,8 @@
 module github.com/mendersoftware/mendertesting
 
-go 1.14
+go 1.16
 
-require github.com/stretchr/testify v1.11.1
+require (
+	github.com/stretchr/testify v1.11.1
+	gopkg.in/yaml.v3 v3.0.1
+)
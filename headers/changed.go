@@ -0,0 +1,69 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package headers
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"github.com/mendersoftware/mendertesting/internal/git"
+)
+
+// changedFiles returns the set of slash separated paths, relative to Root,
+// of the files changed since ChangedSince, or nil if all files must be
+// checked.
+func (c *Checker) changedFiles(ctx context.Context) (map[string]bool, error) {
+	if c.ChangedSince == "" {
+		return nil, nil
+	}
+	root := c.root()
+	since, err := git.Output(ctx, root, "rev-parse", "--verify", "--end-of-options",
+		c.ChangedSince+"^{commit}")
+	if err != nil {
+		return nil, err
+	}
+	since = strings.TrimSpace(since)
+	if c.FirstEnterpriseCommit != "" &&
+		!git.Succeeds(ctx, root, "merge-base", "--is-ancestor", c.FirstEnterpriseCommit, since) {
+		// The fork point moved, which may change the license class of
+		// any file.
+		return nil, nil
+	}
+	// Deleted files are listed as well, so that removing a policy file
+	// triggers a full check. They are never walked.
+	names, err := git.Lines(ctx, root, "-c", "core.quotePath=false",
+		"diff", "--name-only", "--relative", "--no-renames", since, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := git.Lines(ctx, root, "-c", "core.quotePath=false",
+		"ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	policy := map[string]bool{IgnoreFile: true}
+	for _, name := range c.PolicyFiles {
+		policy[strings.TrimPrefix(path.Clean("/"+name), "/")] = true
+	}
+	changed := make(map[string]bool)
+	for _, name := range append(names, untracked...) {
+		if policy[name] {
+			return nil, nil
+		}
+		changed[name] = true
+	}
+	return changed, nil
+}
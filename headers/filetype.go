@@ -0,0 +1,225 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package headers
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// CommentStyle is the way the license header is commented out.
+type CommentStyle struct {
+	// Prefix starts every line of the header, like "//" or "#".
+	Prefix string
+	// Open and Close are lines of their own before and after the header,
+	// for block comments.
+	Open  string
+	Close string
+}
+
+// The comment styles of the built-in file types.
+var (
+	SlashStyle = CommentStyle{Prefix: "//"}
+	HashStyle  = CommentStyle{Prefix: "#"}
+	DashStyle  = CommentStyle{Prefix: "--"}
+	BlockStyle = CommentStyle{Open: "/*", Prefix: " *", Close: " */"}
+)
+
+// FileType is a kind of source file which carries a license header.
+type FileType struct {
+	// Name is used to opt in to the type, see Checker.Types.
+	Name  string
+	Style CommentStyle
+	// Extensions, like ".go", select the files of this type.
+	Extensions []string
+	// Filenames select files by their base name. A trailing "*" matches
+	// any suffix, like in "Dockerfile.*".
+	Filenames []string
+	// Interpreters select files without an extension by the program in
+	// their "#!" line, like "ruby" in "#!/usr/bin/env ruby".
+	Interpreters []string
+	// Default types are always checked, the others only if opted in.
+	Default bool
+}
+
+var (
+	fileTypesLock sync.RWMutex
+	fileTypes     = map[string]FileType{}
+)
+
+func init() {
+	for _, t := range []FileType{
+		{Name: "go", Style: SlashStyle, Extensions: []string{".go"}, Default: true},
+		{Name: "c", Style: SlashStyle, Extensions: []string{".c", ".h"}, Default: true},
+		{Name: "cpp", Style: SlashStyle, Extensions: []string{".cpp", ".hpp"}, Default: true},
+		{Name: "python", Style: HashStyle, Extensions: []string{".py"}, Default: true},
+		{Name: "shell", Style: HashStyle, Extensions: []string{".sh"}, Default: true},
+
+		{Name: "cc", Style: SlashStyle, Extensions: []string{".cc", ".hh", ".cxx", ".hxx"}},
+		{Name: "ruby", Style: HashStyle, Extensions: []string{".rb"},
+			Interpreters: []string{"ruby"}},
+		{Name: "awk", Style: HashStyle, Extensions: []string{".awk"},
+			Interpreters: []string{"awk", "gawk", "mawk"}},
+		{Name: "yaml", Style: HashStyle, Extensions: []string{".yml", ".yaml"}},
+		{Name: "dockerfile", Style: HashStyle, Extensions: []string{".dockerfile"},
+			Filenames: []string{"Dockerfile", "Dockerfile.*"}},
+		{Name: "makefile", Style: HashStyle, Extensions: []string{".mk"},
+			Filenames: []string{"Makefile", "makefile", "GNUmakefile"}},
+		{Name: "javascript", Style: SlashStyle, Extensions: []string{".js", ".mjs", ".cjs"},
+			Interpreters: []string{"node"}},
+		{Name: "typescript", Style: SlashStyle, Extensions: []string{".ts", ".tsx"}},
+		{Name: "rust", Style: SlashStyle, Extensions: []string{".rs"}},
+		{Name: "lua", Style: DashStyle, Extensions: []string{".lua"},
+			Interpreters: []string{"lua"}},
+		{Name: "cmake", Style: HashStyle, Extensions: []string{".cmake"},
+			Filenames: []string{"CMakeLists.txt"}},
+		{Name: "css", Style: BlockStyle, Extensions: []string{".css"}},
+	} {
+		RegisterFileType(t)
+	}
+}
+
+// RegisterFileType adds a file type, or replaces the one with the same
+// name, for example to check C files with BlockStyle headers.
+func RegisterFileType(t FileType) {
+	fileTypesLock.Lock()
+	defer fileTypesLock.Unlock()
+	fileTypes[t.Name] = t
+}
+
+// FileTypes returns the names of all registered file types, sorted.
+func FileTypes() []string {
+	fileTypesLock.RLock()
+	defer fileTypesLock.RUnlock()
+	names := make([]string, 0, len(fileTypes))
+	for name := range fileTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SplitTypes splits a list of file types separated by commas or white
+// space.
+func SplitTypes(list string) []string {
+	return strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+func lookupFileType(name string) (FileType, bool) {
+	fileTypesLock.RLock()
+	defer fileTypesLock.RUnlock()
+	t, ok := fileTypes[name]
+	return t, ok
+}
+
+// fileTypeSet is the set of file types a Checker looks at.
+type fileTypeSet []FileType
+
+// enabledFileTypes returns the default types and the named ones, sorted by
+// name so that matching is deterministic.
+func enabledFileTypes(names []string) (fileTypeSet, error) {
+	fileTypesLock.RLock()
+	defer fileTypesLock.RUnlock()
+	enabled := map[string]FileType{}
+	for _, t := range fileTypes {
+		if t.Default {
+			enabled[t.Name] = t
+		}
+	}
+	for _, name := range names {
+		t, ok := fileTypes[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown file type %q", name)
+		}
+		enabled[name] = t
+	}
+	set := make(fileTypeSet, 0, len(enabled))
+	for _, t := range enabled {
+		set = append(set, t)
+	}
+	sort.Slice(set, func(i, j int) bool { return set[i].Name < set[j].Name })
+	return set, nil
+}
+
+// needsInterpreter reports whether any of the types match by "#!" line.
+func (s fileTypeSet) needsInterpreter() bool {
+	for _, t := range s {
+		if len(t.Interpreters) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns the type of the file with the given slash separated name.
+// The interpreter is only consulted for files without an extension.
+func (s fileTypeSet) match(name, interpreter string) (FileType, bool) {
+	base := path.Base(name)
+	for _, t := range s {
+		for _, pattern := range t.Filenames {
+			if base == pattern || (strings.HasSuffix(pattern, "*") &&
+				strings.HasPrefix(base, strings.TrimSuffix(pattern, "*"))) {
+				return t, true
+			}
+		}
+	}
+	ext := path.Ext(base)
+	for _, t := range s {
+		for _, e := range t.Extensions {
+			if ext == e {
+				return t, true
+			}
+		}
+	}
+	if ext != "" || interpreter == "" {
+		return FileType{}, false
+	}
+	for _, t := range s {
+		for _, i := range t.Interpreters {
+			if interpreter == i {
+				return t, true
+			}
+		}
+	}
+	return FileType{}, false
+}
+
+// interpreter returns the program in a "#!" line, skipping env(1), or ""
+// if the line is not a "#!" line.
+func interpreter(line string) string {
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+	program := path.Base(fields[0])
+	if program == "env" {
+		for _, arg := range fields[1:] {
+			if !strings.HasPrefix(arg, "-") {
+				return path.Base(arg)
+			}
+		}
+		return ""
+	}
+	return program
+}
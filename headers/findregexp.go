@@ -0,0 +1,51 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package headers
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CompileFindRegexp compiles a regular expression in the syntax of
+// find(1) -regex, which is what LICENSE_HEADERS_IGNORE_FILES_REGEXP has
+// always been. Like find, the expression must match the whole path, which
+// starts with "./". In this (Emacs) syntax, `\(`, `\)`, `\|`, `\{` and `\}`
+// are operators, while the unescaped characters are literals.
+func CompileFindRegexp(expr string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^(?:")
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		if c == '\\' && i+1 < len(expr) {
+			i++
+			switch next := expr[i]; next {
+			case '(', ')', '|', '{', '}':
+				b.WriteByte(next)
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(next)
+			}
+			continue
+		}
+		switch c {
+		case '(', ')', '|', '{', '}':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	b.WriteString(")$")
+	return regexp.Compile(b.String())
+}
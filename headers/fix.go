@@ -0,0 +1,213 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package headers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mendersoftware/mendertesting/internal/diff"
+)
+
+// Change is the fix of the header of a single file.
+type Change struct {
+	Finding Finding
+	// Old and New are the contents of the file before and after the fix.
+	Old string
+	New string
+}
+
+// Diff returns the change as a unified diff.
+func (c Change) Diff() string {
+	return diff.Unified(c.Finding.Path, c.Old, c.New)
+}
+
+// Fix fixes the header of every file with a finding: a missing or wrong
+// header is replaced by the header of the file's license class, and an
+// outdated copyright year is raised to the year the file was added to git.
+// Generated files are left to their generator. The changes are returned
+// sorted by path, and only written if dryRun is false.
+func (c *Checker) Fix(ctx context.Context, dryRun bool) ([]Change, error) {
+	findings, err := c.Check(ctx)
+	if err != nil {
+		return nil, err
+	}
+	styles, err := c.styles()
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for _, finding := range findings {
+		if finding.Generated {
+			continue
+		}
+		name := filepath.Join(c.Root, filepath.FromSlash(finding.Path))
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		fixed := fixHeader(finding, string(content), c.headerFor(finding.Path, finding.Class, styles))
+		if fixed == string(content) {
+			continue
+		}
+		changes = append(changes, Change{Finding: finding, Old: string(content), New: fixed})
+		if dryRun {
+			continue
+		}
+		if err := os.WriteFile(name, []byte(fixed), info.Mode().Perm()); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+var anyCopyrightRegexp = regexp.MustCompile(`(?i)Copyright\D*(\d{4})`)
+
+// fixHeader returns the content with the expected header fixed for the
+// finding.
+func fixHeader(f Finding, content string, expected expectedHeader) string {
+	fileType, _ := lookupFileType(f.Type)
+	style := fileType.Style
+	lines := strings.SplitAfter(content, "\n")
+	trimmed := make([]string, len(lines))
+	for i, line := range lines {
+		trimmed[i] = strings.TrimRight(line, "\r\n")
+	}
+	start := preamble(fileType.Name, trimmed)
+
+	copyright := expected.template.copyrightRegexp(regexp.QuoteMeta(expected.holder))
+	if f.Rule == RuleCopyrightYear {
+		// All years are older than the file, so the latest one is replaced,
+		// keeping the start of a range or list.
+		if template := expected.match(trimmed[start:], style); template != nil {
+			copyright = template.copyrightRegexp(regexp.QuoteMeta(expected.holder))
+		}
+		prefix := style.Prefix + " "
+		for i := start; i < len(lines); i++ {
+			loc := copyright.FindStringSubmatchIndex(commentText(trimmed[i], style))
+			if loc == nil {
+				continue
+			}
+			years := yearRegexp.FindAllStringIndex(commentText(trimmed[i], style)[loc[2]:loc[3]], -1)
+			last := years[len(years)-1]
+			yearStart := len(prefix) + loc[2] + last[0]
+			yearEnd := len(prefix) + loc[2] + last[1]
+			lines[i] = lines[i][:yearStart] + strconv.Itoa(f.AddedYear) + lines[i][yearEnd:]
+			return strings.Join(lines, "")
+		}
+		// Only other holders have a copyright line, so the header is
+		// rewritten with ours below.
+	}
+
+	// An existing header is replaced, keeping the copyright lines of other
+	// holders, and its years if they are recent enough.
+	years := strconv.Itoa(f.AddedYear)
+	var others []string
+	end := existingHeaderEnd(trimmed, start, style)
+	if end > start {
+		other := expected.template.copyrightRegexp(anyHolder)
+		found := false
+		for _, line := range trimmed[start:end] {
+			text := commentText(line, style)
+			if m := copyright.FindStringSubmatch(text); m != nil && !found {
+				found = true
+				if latestYear(m[1]) >= f.AddedYear {
+					years = m[1]
+				}
+			} else if other.MatchString(text) {
+				others = append(others, text)
+			}
+		}
+		if !found {
+			for _, line := range trimmed[start:end] {
+				if other.MatchString(commentText(line, style)) {
+					continue
+				}
+				if m := anyCopyrightRegexp.FindStringSubmatch(line); m != nil {
+					if y, _ := strconv.Atoi(m[1]); y > f.AddedYear {
+						years = m[1]
+					}
+					break
+				}
+			}
+		}
+	}
+
+	newline := "\n"
+	if strings.HasSuffix(lines[0], "\r\n") {
+		newline = "\r\n"
+	}
+	header := expected.template.header(style, years, expected.holder, others)
+	// The header is separated from the code by an empty line.
+	if end < len(lines) && trimmed[end] != "" {
+		header = append(header, "")
+	}
+	// The preamble may end in the middle of the last line, if the file
+	// doesn't end with a newline.
+	if start > 0 && !strings.HasSuffix(lines[start-1], "\n") {
+		lines[start-1] += newline
+	}
+
+	result := append([]string(nil), lines[:start]...)
+	for _, line := range header {
+		result = append(result, line+newline)
+	}
+	result = append(result, lines[end:]...)
+	return strings.Join(result, "")
+}
+
+// existingHeaderEnd returns the end of the comment with a copyright notice
+// at start, or start if there is none. For line comments, that is the
+// block of lines starting with the prefix, for block comments the end of
+// the block.
+func existingHeaderEnd(lines []string, start int, style CommentStyle) int {
+	if start >= len(lines) {
+		return start
+	}
+	if style.Open != "" {
+		if !strings.HasPrefix(strings.TrimSpace(lines[start]), style.Open) {
+			return start
+		}
+		closing := strings.TrimSpace(style.Close)
+		copyright := false
+		for end := start; end < len(lines); end++ {
+			copyright = copyright || anyCopyrightRegexp.MatchString(lines[end])
+			if strings.HasSuffix(strings.TrimSpace(lines[end]), closing) {
+				if copyright {
+					return end + 1
+				}
+				return start
+			}
+		}
+		return start
+	}
+	if !strings.HasPrefix(lines[start], style.Prefix) ||
+		!anyCopyrightRegexp.MatchString(lines[start]) {
+		return start
+	}
+	end := start
+	for end < len(lines) && lines[end] != "" && strings.HasPrefix(lines[end], style.Prefix) {
+		end++
+	}
+	return end
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package headers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GeneratedRule is how generated files are checked.
+type GeneratedRule string
+
+const (
+	// SkipGenerated doesn't check generated files.
+	SkipGenerated GeneratedRule = "skip"
+	// CheckGenerated checks generated files against the generated
+	// templates of the Checker, or like any other file without them. The
+	// copyright year is not checked, since it is up to the generator.
+	CheckGenerated GeneratedRule = "check"
+)
+
+// generatedRegexp matches a comment line marking a generated file: the Go
+// convention "// Code generated ... DO NOT EDIT.", which tools like protoc,
+// mockery and stringer follow in other languages as well, and "@generated".
+var generatedRegexp = regexp.MustCompile(
+	`^\s*(?://|#|--|/?\*)\s*(?:Code generated .* DO NOT EDIT\b|@generated\b)`)
+
+// generatedRule returns the rule for generated files, or an error for an
+// unknown one.
+func (c *Checker) generatedRule() (GeneratedRule, error) {
+	switch c.Generated {
+	case "":
+		return SkipGenerated, nil
+	case SkipGenerated, CheckGenerated:
+		return c.Generated, nil
+	}
+	return "", fmt.Errorf("unknown rule for generated files %q", c.Generated)
+}
+
+// lineCommentRegexp matches a line comment in any of the comment styles.
+var lineCommentRegexp = regexp.MustCompile(`^(//|#|--)`)
+
+// isGenerated reports whether the file is marked as generated in its leading
+// comments, like the Go convention requires. A marker further down, after
+// the code starts, doesn't count.
+func (c *Checker) isGenerated(lines []string) bool {
+	for _, line := range leadingComments(lines) {
+		if generatedRegexp.MatchString(line) {
+			return true
+		}
+		for _, re := range c.GeneratedMarkers {
+			if re.MatchString(line) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// leadingComments returns the lines before the first line of code: empty
+// lines, line comments and block comments.
+func leadingComments(lines []string) []string {
+	inBlock := false
+	for i, line := range lines {
+		text := strings.TrimSpace(line)
+		switch {
+		case inBlock:
+			inBlock = !strings.Contains(text, "*/")
+		case text == "" || lineCommentRegexp.MatchString(text):
+		case strings.HasPrefix(text, "/*"):
+			inBlock = !strings.Contains(text[2:], "*/")
+		default:
+			return lines[:i]
+		}
+	}
+	return lines
+}
+
+// generatedHeader returns the expected header of a generated file in the
+// license class, which is the regular one without a generated template.
+func (c *Checker) generatedHeader(h expectedHeader, class Class) expectedHeader {
+	template := c.GeneratedOpenSource
+	if class == Enterprise {
+		template = c.GeneratedEnterprise
+	}
+	if template == nil {
+		return h
+	}
+	return expectedHeader{template: template, holder: h.holder}
+}
@@ -0,0 +1,448 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package headers checks that the source files of a Mender repository carry
+// the correct Open Source or Enterprise license header, and that the
+// copyright year is not older than the year the file was added to git.
+//
+// The header is the first comment of the file. Only the lines which must
+// come first may precede it: a "#!" line, a Python or Ruby encoding cookie,
+// Go build constraints and Dockerfile parser directives. The fixer inserts
+// the header after them.
+//
+// It implements the same rules as check_license_source_files.sh, without
+// writing temporary files and without depending on anything but git.
+package headers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mendersoftware/mendertesting/internal/ignore"
+)
+
+// Rule identifies which part of a header check failed.
+type Rule string
+
+const (
+	// RuleLicense means that the license text is missing or wrong.
+	RuleLicense Rule = "license"
+	// RuleCopyrightYear means that the copyright year is older than the
+	// year the file was added to git.
+	RuleCopyrightYear Rule = "copyright-year"
+)
+
+// Finding is a problem with the header of a single file.
+type Finding struct {
+	// Path is slash separated and relative to the checked root.
+	Path string
+	// Type is the name of the file type, see FileType.
+	Type string
+	// Class is the license class that the file is expected to have.
+	Class   Class
+	Rule    Rule
+	Message string
+	// Expected is the expected license text, for RuleLicense findings.
+	Expected string
+	// AddedYear is the year the file was first added to git.
+	AddedYear int
+	// Generated files are not fixed, their generator is.
+	Generated bool
+}
+
+func (f Finding) String() string {
+	if f.Expected != "" {
+		return fmt.Sprintf("%s: %s:\n%s", f.Path, f.Message, f.Expected)
+	}
+	return fmt.Sprintf("%s: %s", f.Path, f.Message)
+}
+
+// IgnoreFile is the file, at the root of the repository, with patterns in
+// the gitignore(5) format of files which are not checked.
+const IgnoreFile = ".licenseignore"
+
+// Checker checks the license headers of all supported source files below
+// Root, except the ones matching the patterns in IgnoreFile.
+type Checker struct {
+	// Root is the root of the git repository to check. Defaults to the
+	// current working directory.
+	Root string
+
+	// FirstEnterpriseCommit is the very first commit after the fork point
+	// on the Enterprise branch, if this is an Enterprise repository. Files
+	// which don't exist in the latest Open Source commit must carry the
+	// Enterprise header.
+	FirstEnterpriseCommit string
+
+	// Ignore excludes files whose path, in the form "./<path>", matches
+	// any of the expressions, in addition to IgnoreFile. See
+	// CompileFindRegexp.
+	Ignore []*regexp.Regexp
+
+	// Types are the names of the file types to check besides the default
+	// ones. See FileTypes.
+	Types []string
+
+	// CopyrightHolder is the holder in the copyright line. Defaults to
+	// DefaultCopyrightHolder.
+	CopyrightHolder string
+
+	// Templates select other header templates and copyright holders for
+	// the files below some directories. Without them, files carry the
+	// DefaultTemplate of their license class.
+	Templates []DirTemplate
+
+	// Styles are the accepted header styles, only FullText if empty. The
+	// fixer writes the first one, and converts headers of other styles.
+	// Templates of a directory replace the headers of all styles.
+	Styles []HeaderStyle
+	// SPDXOpenSource and SPDXEnterprise are the license identifiers of
+	// SPDX headers. Default to DefaultSPDXOpenSource and
+	// DefaultSPDXEnterprise.
+	SPDXOpenSource string
+	SPDXEnterprise string
+
+	// Generated is how files marked as generated are checked, see
+	// GeneratedRule. Defaults to SkipGenerated.
+	Generated GeneratedRule
+	// GeneratedMarkers match lines which mark generated files, besides
+	// "Code generated ... DO NOT EDIT." and "@generated" comments.
+	GeneratedMarkers []*regexp.Regexp
+	// GeneratedOpenSource and GeneratedEnterprise are the templates of
+	// generated files with the CheckGenerated rule, like the header of
+	// their generator's template.
+	GeneratedOpenSource *Template
+	GeneratedEnterprise *Template
+
+	// ChangedSince limits the check to the files added, renamed or
+	// modified since this revision, including uncommitted and untracked
+	// ones. All files are checked anyway if one of the PolicyFiles or
+	// IgnoreFile changed, or if FirstEnterpriseCommit isn't an ancestor
+	// of the revision.
+	ChangedSince string
+	// PolicyFiles are slash separated paths, relative to Root, of the
+	// files configuring the check, like header templates.
+	PolicyFiles []string
+}
+
+// DefaultCopyrightHolder is the copyright holder of Mender source files.
+const DefaultCopyrightHolder = "Northern.tech AS"
+
+func (c *Checker) root() string {
+	if c.Root == "" {
+		return "."
+	}
+	return c.Root
+}
+
+func (c *Checker) holder() string {
+	if c.CopyrightHolder == "" {
+		return DefaultCopyrightHolder
+	}
+	return c.CopyrightHolder
+}
+
+// sourceFile is a file subject to the header check.
+type sourceFile struct {
+	name     string
+	fileType FileType
+}
+
+// Check checks all files and returns the findings, sorted by path. The
+// error is only non-nil if the check itself could not be carried out.
+func (c *Checker) Check(ctx context.Context) ([]Finding, error) {
+	styles, err := c.styles()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.generatedRule(); err != nil {
+		return nil, err
+	}
+	files, err := c.files(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hist, err := newHistory(ctx, c.Root, c.FirstEnterpriseCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, file := range files {
+		finding, err := c.checkFile(hist, styles, file)
+		if err != nil {
+			return nil, err
+		}
+		if finding != nil {
+			findings = append(findings, *finding)
+		}
+	}
+	return findings, nil
+}
+
+// Files returns the slash separated paths of all files which are subject to
+// the header check, sorted.
+func (c *Checker) Files() ([]string, error) {
+	files, err := c.files(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = file.name
+	}
+	return names, nil
+}
+
+func (c *Checker) files(ctx context.Context) ([]sourceFile, error) {
+	types, err := enabledFileTypes(c.Types)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := c.changedFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	root := c.root()
+	ignored, err := ignore.ReadFile(filepath.Join(root, IgnoreFile))
+	if err != nil {
+		return nil, err
+	}
+	var files []sourceFile
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel == ".git" || d.Name() == "vendor" ||
+				(rel != "." && ignored.Match(rel, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || ignored.Match(rel, false) {
+			return nil
+		}
+		if changed != nil && !changed[rel] {
+			return nil
+		}
+		program := ""
+		if path.Ext(rel) == "" && types.needsInterpreter() {
+			line, err := firstLine(p)
+			if err != nil {
+				return err
+			}
+			program = interpreter(line)
+		}
+		fileType, ok := types.match(rel, program)
+		if !ok || !c.included(rel, fileType) {
+			return nil
+		}
+		files = append(files, sourceFile{name: rel, fileType: fileType})
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+	return files, err
+}
+
+func (c *Checker) included(name string, fileType FileType) bool {
+	findPath := "./" + name
+	for _, re := range c.Ignore {
+		if re.MatchString(findPath) {
+			return false
+		}
+	}
+	if fileType.Name == "python" {
+		// Virtual environments and build output are never checked.
+		if strings.Contains(findPath, ".venv") || strings.Contains(findPath, "build/") {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Checker) checkFile(
+	hist *history,
+	styles []HeaderStyle,
+	file sourceFile,
+) (*Finding, error) {
+	name := file.name
+	style := file.fileType.Style
+	lines, err := readLines(filepath.Join(c.Root, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, err
+	}
+	generated := c.isGenerated(lines)
+	if generated && c.Generated != CheckGenerated {
+		return nil, nil
+	}
+
+	class := OpenSource
+	if hist.isEnterprise(name) {
+		class = Enterprise
+	}
+	addedYear := hist.addedYear(name)
+
+	lines = lines[preamble(file.fileType.Name, lines):]
+
+	expected := c.headerFor(name, class, styles)
+	if generated {
+		expected = c.generatedHeader(expected, class)
+	}
+	template := expected.match(lines, style)
+	if template == nil {
+		body := expected.template.body(style, expected.holder)
+		// The empty lines after the copyright line are left out.
+		for len(body) > 0 && body[0] == style.Prefix {
+			body = body[1:]
+		}
+		return &Finding{
+			Path:      name,
+			Type:      file.fileType.Name,
+			Class:     class,
+			Rule:      RuleLicense,
+			Message:   fmt.Sprintf("Expected this %s license", class),
+			Expected:  strings.Join(body, "\n"),
+			AddedYear: addedYear,
+			Generated: generated,
+		}, nil
+	}
+	if generated {
+		return nil, nil
+	}
+
+	// The latest year of a range or list counts.
+	copyright := template.copyrightRegexp(regexp.QuoteMeta(expected.holder))
+	copyrightYear := 0
+	for _, line := range lines {
+		if m := copyright.FindStringSubmatch(commentText(line, style)); m != nil {
+			copyrightYear = latestYear(m[1])
+			break
+		}
+	}
+	if copyrightYear < addedYear {
+		message := fmt.Sprintf("make sure copyright year is at least the year "+
+			"the file was first added to git (%d)", addedYear)
+		if copyrightYear == 0 {
+			message = fmt.Sprintf("missing the copyright line of %s", expected.holder)
+		}
+		return &Finding{
+			Path:      name,
+			Type:      file.fileType.Name,
+			Class:     class,
+			Rule:      RuleCopyrightYear,
+			Message:   message,
+			AddedYear: addedYear,
+		}, nil
+	}
+	return nil, nil
+}
+
+// headerMatches reports whether the body of the header follows the
+// copyright lines, inside the block comment of the style, if it has one.
+// Without any copyright line of the template, the first line is taken as
+// the copyright line, to be checked by the copyright year rule. Tabs are
+// accepted in place of four spaces.
+func headerMatches(lines []string, style CommentStyle, template *Template, body []string) bool {
+	start := 0
+	if style.Open != "" {
+		if len(lines) == 0 || lines[0] != style.Open {
+			return false
+		}
+		start++
+	}
+	if n := template.copyrightLines(lines[start:], style); n > 0 {
+		start += n
+	} else {
+		start++
+	}
+	expected := body
+	if style.Close != "" {
+		expected = append(expected[:len(expected):len(expected)], style.Close)
+	}
+	if len(lines) < start+len(expected) {
+		return false
+	}
+	for i, line := range lines[start : start+len(expected)] {
+		if strings.ReplaceAll(line, "\t", "    ") != expected[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// firstLine returns the first line of a file, or as much of it as fits into
+// a small buffer.
+func firstLine(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	buf := make([]byte, 256)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	line := string(buf[:n])
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	return line, nil
+}
+
+// maxLineLength is the length up to which lines are read. The rest of longer
+// lines, like in minified files, can't be part of a header and is skipped.
+const maxLineLength = 64 * 1024
+
+func readLines(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	reader := bufio.NewReaderSize(f, maxLineLength)
+	for {
+		line, more, err := reader.ReadLine()
+		if err == io.EOF {
+			return lines, nil
+		} else if err != nil {
+			return nil, err
+		}
+		lines = append(lines, string(line))
+		for more {
+			if _, more, err = reader.ReadLine(); err == io.EOF {
+				return lines, nil
+			} else if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
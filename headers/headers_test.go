@@ -0,0 +1,819 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package headers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func osHeader(marker string, year int) string {
+	return fmt.Sprintf("%s Copyright %d Northern.tech AS\n", marker, year) + strings.Join(
+		DefaultTemplate(OpenSource).body(CommentStyle{Prefix: marker}, "unused"), "\n") + "\n"
+}
+
+func entHeader(marker string, year int) string {
+	return fmt.Sprintf("%s Copyright %d Northern.tech AS\n", marker, year) + strings.Join(
+		DefaultTemplate(Enterprise).body(CommentStyle{Prefix: marker}, "unused"), "\n") + "\n"
+}
+
+type testRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	r := &testRepo{t: t, dir: t.TempDir()}
+	r.git("init", "-q", "-b", "master")
+	return r
+}
+
+// gitAt runs git in the repository, with the given date as author and
+// committer date, if any.
+func (r *testRepo) gitAt(date string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test Testison",
+		"GIT_AUTHOR_EMAIL=test@test.com",
+		"GIT_COMMITTER_NAME=Test Testison",
+		"GIT_COMMITTER_EMAIL=test@test.com",
+	)
+	if date != "" {
+		cmd.Env = append(cmd.Env, "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	}
+	output, err := cmd.CombinedOutput()
+	require.NoError(r.t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+func (r *testRepo) git(args ...string) string {
+	return r.gitAt("", args...)
+}
+
+func (r *testRepo) write(name, content string) {
+	p := filepath.Join(r.dir, filepath.FromSlash(name))
+	require.NoError(r.t, os.MkdirAll(filepath.Dir(p), 0755))
+	require.NoError(r.t, os.WriteFile(p, []byte(content), 0644))
+}
+
+func (r *testRepo) commitAt(date, msg string) string {
+	r.git("add", "-A")
+	r.gitAt(date, "commit", "-q", "-m", msg)
+	return r.git("rev-parse", "HEAD")
+}
+
+func paths(findings []Finding) map[string]Rule {
+	result := map[string]Rule{}
+	for _, f := range findings {
+		result[f.Path] = f.Rule
+	}
+	return result
+}
+
+func TestChecker(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("good.go", osHeader("//", 2020)+"\npackage good\n")
+	r.write("script.sh", "#!/bin/bash\n"+osHeader("#", 2020)+"\necho hello\n")
+	r.write("tabbed.py", strings.ReplaceAll(osHeader("#", 2020), "    ", "\t"))
+	r.write("sub/wrong.c", "// Copyright 2020 Northern.tech AS\n//\n// MIT\n")
+	r.write("old.hpp", osHeader("//", 2019))
+	r.write("vendor/x/y.go", "package y\n")
+	r.write(".venv/lib/z.py", "import os\n")
+	r.write("ignored/file.go", "package ignored\n")
+	r.write("README.md", "Not checked\n")
+	r.commitAt("2020-06-01T12:00:00", "Initial commit")
+
+	ignore, err := CompileFindRegexp(`\./ignored/.*`)
+	require.NoError(t, err)
+	checker := &Checker{Root: r.dir, Ignore: []*regexp.Regexp{ignore}}
+
+	files, err := checker.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"good.go", "old.hpp", "script.sh", "sub/wrong.c", "tabbed.py"},
+		files)
+
+	findings, err := checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{
+		"sub/wrong.c": RuleLicense,
+		"old.hpp":     RuleCopyrightYear,
+	}, paths(findings))
+	for _, f := range findings {
+		assert.Equal(t, 2020, f.AddedYear)
+		assert.Equal(t, OpenSource, f.Class)
+	}
+
+	// A file which is renamed keeps the year it was first added.
+	r.git("mv", "good.go", "renamed.go")
+	r.commitAt("2022-06-01T12:00:00", "Rename")
+	findings, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.NotContains(t, paths(findings), "renamed.go")
+
+	// A new file must carry the year it is added. Give it enough content
+	// of its own to not be detected as a copy.
+	r.write("new.go", osHeader("//", 2021)+strings.Repeat("\n// Unique content", 40))
+	r.commitAt("2022-06-01T12:00:00", "New file")
+	findings, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, RuleCopyrightYear, paths(findings)["new.go"])
+}
+
+func TestCheckerIgnoreFile(t *testing.T) {
+	r := newTestRepo(t)
+	r.write(IgnoreFile, "# Generated\n*.pb.go\n!keep.pb.go\nthird_party/\n")
+	r.write("a.go", "package a\n")
+	r.write("a.pb.go", "package a\n")
+	r.write("sub/keep.pb.go", "package sub\n")
+	r.write("third_party/x/x.go", "package x\n")
+	r.write("scripts/gen.py", "import os\n")
+	r.commitAt("2020-06-01T12:00:00", "Initial commit")
+
+	checker := &Checker{Root: r.dir}
+	files, err := checker.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.go", "scripts/gen.py", "sub/keep.pb.go"}, files)
+
+	// The find(1) regexp still applies on top.
+	ignore, err := CompileFindRegexp(`\./scripts/.*`)
+	require.NoError(t, err)
+	checker.Ignore = []*regexp.Regexp{ignore}
+	files, err = checker.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.go", "sub/keep.pb.go"}, files)
+}
+
+func TestCheckerEnterprise(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("os-file.go", osHeader("//", 2020))
+	r.commitAt("2020-06-01T12:00:00", "Initial OS revision")
+	r.git("checkout", "-q", "-b", "os")
+
+	r.git("checkout", "-q", "master")
+	r.write("ent-file.go", entHeader("//", 2020))
+	entCommit := r.commitAt("2020-06-02T12:00:00", "Initial ENT revision")
+
+	checker := &Checker{Root: r.dir, FirstEnterpriseCommit: entCommit}
+	findings, err := checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	// Merge more Open Source into Enterprise.
+	r.git("checkout", "-q", "os")
+	r.write("os-file2.go", osHeader("//", 2020))
+	r.commitAt("2020-06-03T12:00:00", "More OS files")
+	r.git("checkout", "-q", "master")
+	r.gitAt("2020-06-04T12:00:00", "merge", "-q", "-m", "Merge OS", "os")
+
+	findings, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	// An Open Source header is not allowed on Enterprise files.
+	r.write("ent-file2.go", osHeader("//", 2020))
+	r.commitAt("2020-06-05T12:00:00", "Wrong ENT file")
+	findings, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "ent-file2.go", findings[0].Path)
+	assert.Equal(t, Enterprise, findings[0].Class)
+	assert.Equal(t, RuleLicense, findings[0].Rule)
+
+	// Without an Enterprise commit everything is Open Source.
+	checker.FirstEnterpriseCommit = ""
+	findings, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{"ent-file.go": RuleLicense}, paths(findings))
+}
+
+func TestCompileFindRegexp(t *testing.T) {
+	testCases := []struct {
+		expr    string
+		match   []string
+		noMatch []string
+	}{
+		{
+			expr:    "none",
+			noMatch: []string{"./none", "./a.go"},
+		},
+		{
+			expr:    `\./exclude-this/.*\.py`,
+			match:   []string{"./exclude-this/a.py", "./exclude-this/b/c.py"},
+			noMatch: []string{"./exclude-this/a.go", "./other/exclude-this/a.py"},
+		},
+		{
+			expr:    `\./a\.go\|.*/mocks/.*`,
+			match:   []string{"./a.go", "./x/mocks/y.go"},
+			noMatch: []string{"./b.go"},
+		},
+		{
+			expr:    `\./\(foo\|bar\)/(x)|y.go`,
+			match:   []string{"./foo/(x)|y.go", "./bar/(x)|y.go"},
+			noMatch: []string{"./foo/x.go"},
+		},
+	}
+	for _, tc := range testCases {
+		re, err := CompileFindRegexp(tc.expr)
+		require.NoError(t, err, tc.expr)
+		for _, m := range tc.match {
+			assert.True(t, re.MatchString(m), "%s should match %s", tc.expr, m)
+		}
+		for _, m := range tc.noMatch {
+			assert.False(t, re.MatchString(m), "%s should not match %s", tc.expr, m)
+		}
+	}
+}
+
+func TestFixHeader(t *testing.T) {
+	header := osHeader("//", 2020)
+	testCases := []struct {
+		name    string
+		finding Finding
+		content string
+		fixed   string
+	}{
+		{
+			name:    "missing",
+			finding: Finding{Path: "a.go", Type: "go", Rule: RuleLicense, AddedYear: 2020},
+			content: "package a\n",
+			fixed:   header + "\npackage a\n",
+		},
+		{
+			name:    "empty file",
+			finding: Finding{Path: "a.go", Type: "go", Rule: RuleLicense, AddedYear: 2020},
+			content: "",
+			fixed:   header,
+		},
+		{
+			name:    "wrong license, recent year is kept",
+			finding: Finding{Path: "a.go", Type: "go", Rule: RuleLicense, AddedYear: 2019},
+			content: "// Copyright 2020 Northern.tech AS\n//\n// MIT\n\npackage a\n",
+			fixed:   header + "\npackage a\n",
+		},
+		{
+			name: "Open Source header on Enterprise file",
+			finding: Finding{Path: "a.go", Type: "go", Rule: RuleLicense, Class: Enterprise,
+				AddedYear: 2020},
+			content: header + "\npackage a\n",
+			fixed:   entHeader("//", 2020) + "\npackage a\n",
+		},
+		{
+			name:    "outdated year",
+			finding: Finding{Path: "a.go", Type: "go", Rule: RuleCopyrightYear, AddedYear: 2022},
+			content: header + "\npackage a\n",
+			fixed:   osHeader("//", 2022) + "\npackage a\n",
+		},
+		{
+			name:    "outdated year range",
+			finding: Finding{Path: "a.go", Type: "go", Rule: RuleCopyrightYear, AddedYear: 2022},
+			content: strings.Replace(header, "2020", "2018-2020", 1) + "\npackage a\n",
+			fixed:   strings.Replace(header, "2020", "2018-2022", 1) + "\npackage a\n",
+		},
+		{
+			name:    "outdated year list",
+			finding: Finding{Path: "a.go", Type: "go", Rule: RuleCopyrightYear, AddedYear: 2022},
+			content: strings.Replace(header, "2020", "2015, 2018 - 2020", 1) + "\npackage a\n",
+			fixed:   strings.Replace(header, "2020", "2015, 2018 - 2022", 1) + "\npackage a\n",
+		},
+		{
+			name:    "only another holder's copyright line",
+			finding: Finding{Path: "a.go", Type: "go", Rule: RuleCopyrightYear, AddedYear: 2022},
+			content: strings.Replace(header, "2020 Northern.tech AS", "2015 Original Author", 1) +
+				"\npackage a\n",
+			fixed: "// Copyright 2015 Original Author\n" + osHeader("//", 2022) + "\npackage a\n",
+		},
+		{
+			name:    "wrong license, other holders and year range are kept",
+			finding: Finding{Path: "a.go", Type: "go", Rule: RuleLicense, AddedYear: 2020},
+			content: "// Copyright 2015 Original Author\n// Copyright 2019-2021 Northern.tech AS\n" +
+				"//\n// MIT\n\npackage a\n",
+			fixed: "// Copyright 2015 Original Author\n" +
+				strings.Replace(header, "2020", "2019-2021", 1) + "\npackage a\n",
+		},
+		{
+			name:    "shebang",
+			finding: Finding{Path: "a.sh", Type: "shell", Rule: RuleLicense, AddedYear: 2020},
+			content: "#!/bin/sh\necho hello\n",
+			fixed:   "#!/bin/sh\n" + osHeader("#", 2020) + "\necho hello\n",
+		},
+		{
+			name:    "shebang without newline",
+			finding: Finding{Path: "a.sh", Type: "shell", Rule: RuleLicense, AddedYear: 2020},
+			content: "#!/bin/sh",
+			fixed:   "#!/bin/sh\n" + osHeader("#", 2020),
+		},
+		{
+			name:    "encoding cookie",
+			finding: Finding{Path: "a.py", Type: "python", Rule: RuleLicense, AddedYear: 2020},
+			content: "#!/usr/bin/python3\n# -*- coding: utf-8 -*-\nimport os\n",
+			fixed: "#!/usr/bin/python3\n# -*- coding: utf-8 -*-\n" + osHeader("#", 2020) +
+				"\nimport os\n",
+		},
+		{
+			name:    "build constraints",
+			finding: Finding{Path: "a.go", Type: "go", Rule: RuleLicense, AddedYear: 2020},
+			content: "//go:build linux\n// +build linux\n\npackage a\n",
+			fixed:   "//go:build linux\n// +build linux\n\n" + header + "\npackage a\n",
+		},
+		{
+			name:    "CRLF",
+			finding: Finding{Path: "a.c", Type: "c", Rule: RuleLicense, AddedYear: 2020},
+			content: "int a;\r\n",
+			fixed:   strings.ReplaceAll(header+"\n", "\n", "\r\n") + "int a;\r\n",
+		},
+	}
+	for _, tc := range testCases {
+		expected := expectedHeader{
+			template: DefaultTemplate(tc.finding.Class),
+			holder:   DefaultCopyrightHolder,
+		}
+		assert.Equal(t, tc.fixed, fixHeader(tc.finding, tc.content, expected), tc.name)
+	}
+}
+
+func TestFix(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("good.go", "//go:build linux\n\n"+osHeader("//", 2020)+"\npackage good\n")
+	r.write("missing.go", "package missing\n")
+	r.write("old.py", "# -*- coding: utf-8 -*-\n"+osHeader("#", 2019)+"\nimport os\n")
+	r.write("other.go", strings.Replace(osHeader("//", 2020), "Northern.tech AS",
+		"Original Author", 1)+"\npackage other\n")
+	r.commitAt("2020-06-01T12:00:00", "Initial commit")
+	checker := &Checker{Root: r.dir}
+
+	changes, err := checker.Fix(context.Background(), true)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	assert.Equal(t, "missing the copyright line of Northern.tech AS",
+		changes[2].Finding.Message)
+	assert.Equal(t, "missing.go", changes[0].Finding.Path)
+	assert.Equal(t, "--- a/old.py\n+++ b/old.py\n@@ -1,5 +1,5 @@\n"+
+		" # -*- coding: utf-8 -*-\n"+
+		"-# Copyright 2019 Northern.tech AS\n"+
+		"+# Copyright 2020 Northern.tech AS\n"+
+		" #\n"+
+		" #    Licensed under the Apache License, Version 2.0 (the \"License\");\n"+
+		" #    you may not use this file except in compliance with the License.\n",
+		changes[1].Diff())
+	assert.True(t, strings.HasPrefix(changes[0].Diff(),
+		"--- a/missing.go\n+++ b/missing.go\n@@ -1 +1,15 @@\n+// Copyright 2020"),
+		changes[0].Diff())
+
+	// A dry run leaves the files alone.
+	findings, err := checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, findings, 3)
+
+	changes, err = checker.Fix(context.Background(), false)
+	require.NoError(t, err)
+	assert.Len(t, changes, 3)
+	findings, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestCheckerPreamble(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("constraints.go", "//go:build linux\n// +build linux\n\n"+osHeader("//", 2020)+
+		"\npackage a\n")
+	r.write("cookie.py", "#!/usr/bin/python3\n# -*- coding: utf-8 -*-\n"+osHeader("#", 2020))
+	r.write("late.go", "// Package a does nothing.\n\n"+osHeader("//", 2020)+"\npackage a\n")
+	r.write("late.py", "import os\n"+osHeader("#", 2020))
+	r.commitAt("2020-06-01T12:00:00", "Initial commit")
+
+	findings, err := (&Checker{Root: r.dir}).Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{
+		"late.go": RuleLicense,
+		"late.py": RuleLicense,
+	}, paths(findings))
+}
+
+func TestCheckerLongLines(t *testing.T) {
+	r := newTestRepo(t)
+	long := strings.Repeat("x", 2*1024*1024)
+	r.write("long.go", osHeader("//", 2020)+"\npackage a\n\nvar s = \""+long+"\"\n")
+	r.write("bad.go", "var s = \""+long+"\"\n"+osHeader("//", 2020))
+	r.commitAt("2020-06-01T12:00:00", "Initial commit")
+
+	findings, err := (&Checker{Root: r.dir}).Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{"bad.go": RuleLicense}, paths(findings))
+}
+
+func TestCheckerFileTypes(t *testing.T) {
+	block := "/*\n" + osHeader(" *", 2020) + " */\n"
+	r := newTestRepo(t)
+	r.write("good.go", osHeader("//", 2020))
+	r.write("tool.rb", osHeader("#", 2020))
+	r.write("commitlint", "#!/usr/bin/env gawk -f\n"+osHeader("#", 2020))
+	r.write("script", "#!/bin/sh\necho not a registered interpreter\n")
+	r.write("Dockerfile", "# syntax=docker/dockerfile:1\n"+osHeader("#", 2020)+"FROM alpine\n")
+	r.write("style.css", block+"\nbody {}\n")
+	r.write("broken.css", "/* Copyright 2019 Northern.tech AS */\n\nbody {}\n")
+	r.write("main.lua", "print(1)\n")
+	r.commitAt("2020-06-01T12:00:00", "Initial commit")
+
+	checker := &Checker{Root: r.dir}
+	files, err := checker.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"good.go"}, files)
+
+	checker.Types = []string{"ruby", "awk", "dockerfile", "css", "lua"}
+	files, err = checker.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Dockerfile", "broken.css", "commitlint", "good.go", "main.lua",
+		"style.css", "tool.rb"}, files)
+	findings, err := checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{
+		"broken.css": RuleLicense,
+		"main.lua":   RuleLicense,
+	}, paths(findings))
+
+	changes, err := checker.Fix(context.Background(), false)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, block+"\nbody {}\n", changes[0].New)
+	assert.Equal(t, osHeader("--", 2020)+"\nprint(1)\n", changes[1].New)
+	findings, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	checker.Types = []string{"cobol"}
+	_, err = checker.Files()
+	assert.EqualError(t, err, `unknown file type "cobol"`)
+}
+
+func TestParseTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate("SPDX-FileCopyrightText: {year} {holder}  \r\n\r\nMIT\n\n")
+	require.NoError(t, err)
+	assert.Equal(t, "SPDX-FileCopyrightText: {year} {holder}\n\nMIT\n", tmpl.String())
+	assert.Equal(t, []string{"# SPDX-FileCopyrightText: 2020 Acme (Inc.)", "#", "# MIT"},
+		tmpl.header(HashStyle, "2020", "Acme (Inc.)", nil))
+	m := tmpl.copyrightRegexp(regexp.QuoteMeta("Acme (Inc.)")).FindStringSubmatch(
+		"SPDX-FileCopyrightText: 2021 Acme (Inc.)")
+	require.NotNil(t, m)
+	assert.Equal(t, "2021", m[1])
+
+	for text, msg := range map[string]string{
+		"\n\n":                     "empty header template",
+		"Copyright Acme\n":         "the first line of a header template must contain {year} once",
+		"Copyright {year}\n{year}": "line 2: {year} is only allowed in the first line",
+	} {
+		_, err := ParseTemplate(text)
+		assert.EqualError(t, err, msg)
+	}
+}
+
+func TestCheckerTemplates(t *testing.T) {
+	contrib, err := ParseTemplate("Copyright (C) {year} {holder}\n\nLicensed under the MIT License.\n")
+	require.NoError(t, err)
+
+	r := newTestRepo(t)
+	r.write("main.go", osHeader("//", 2020))
+	r.write("contrib/good.go", "// Copyright (C) 2020 Contributor\n//\n"+
+		"// Licensed under the MIT License.\n")
+	r.write("contrib/missing.go", "package contrib\n")
+	r.write("contrib/acme/good.go", "// Copyright (C) 2020 Acme\n//\n"+
+		"// Licensed under the MIT License.\n")
+	r.write("contrib/acme/old.go", "// Copyright (C) 2019 Acme\n//\n"+
+		"// Licensed under the MIT License.\n")
+	r.write("contribution.go", osHeader("//", 2020))
+	r.commitAt("2020-06-01T12:00:00", "Initial commit")
+
+	checker := &Checker{Root: r.dir, Templates: []DirTemplate{
+		// Less specific directories are overridden, whatever the order.
+		{Dir: "contrib/acme/", CopyrightHolder: "Acme"},
+		{Dir: "./contrib", OpenSource: contrib, CopyrightHolder: "Contributor"},
+	}}
+	findings, err := checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{
+		"contrib/missing.go":  RuleLicense,
+		"contrib/acme/old.go": RuleCopyrightYear,
+	}, paths(findings))
+	for _, f := range findings {
+		if f.Rule == RuleLicense {
+			assert.Equal(t, "// Licensed under the MIT License.", f.Expected)
+		}
+	}
+
+	changes, err := checker.Fix(context.Background(), false)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "// Copyright (C) 2020 Acme\n//\n// Licensed under the MIT License.\n",
+		changes[0].New)
+	assert.Equal(t, "// Copyright (C) 2020 Contributor\n//\n// Licensed under the MIT License.\n"+
+		"\npackage contrib\n", changes[1].New)
+}
+
+func TestCheckerCopyrightYears(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("range.go", strings.Replace(osHeader("//", 2020), "2020", "2018-2020", 1))
+	r.write("list.go", strings.Replace(osHeader("//", 2020), "2020", "2016, 2018 - 2020", 1))
+	r.write("old-range.go", strings.Replace(osHeader("//", 2020), "2020", "2017-2019", 1))
+	r.write("authors.go", "// Copyright 2015 Original Author\n"+
+		"// Copyright 2016-2017 Other Author, Inc.\n"+osHeader("//", 2020))
+	r.write("old-authors.py", "# Copyright 2021 Original Author\n"+osHeader("#", 2019))
+	r.write("block.css", "/*\n * Copyright 2015 Original Author\n"+osHeader(" *", 2020)+" */\n")
+	r.commitAt("2020-06-01T12:00:00", "Initial commit")
+
+	checker := &Checker{Root: r.dir, Types: []string{"css"}}
+	findings, err := checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{
+		"old-range.go":   RuleCopyrightYear,
+		"old-authors.py": RuleCopyrightYear,
+	}, paths(findings))
+
+	changes, err := checker.Fix(context.Background(), true)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "# Copyright 2021 Original Author\n"+osHeader("#", 2020), changes[0].New)
+	assert.Equal(t, strings.Replace(osHeader("//", 2020), "2020", "2017-2020", 1), changes[1].New)
+}
+
+func TestCheckerSPDX(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("full.go", osHeader("//", 2020)+"\npackage full\n")
+	r.write("spdx.py", "# Copyright 2020 Northern.tech AS\n"+
+		"# SPDX-License-Identifier: Apache-2.0\n\nimport os\n")
+	r.write("old.sh", "#!/bin/sh\n# Copyright 2018-2019 Northern.tech AS\n"+
+		"# SPDX-License-Identifier: Apache-2.0\n")
+	r.write("wrong.go", "// Copyright 2020 Northern.tech AS\n// SPDX-License-Identifier: MIT\n")
+	r.commitAt("2020-06-01T12:00:00", "Initial commit")
+	r.write("ent.go", "// Copyright 2020 Northern.tech AS\n"+
+		"// SPDX-License-Identifier: LicenseRef-Acme-Proprietary\n\npackage ent\n")
+	ent := r.commitAt("2020-06-01T12:00:00", "Enterprise commit")
+
+	checker := &Checker{Root: r.dir, FirstEnterpriseCommit: ent}
+	findings, err := checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{
+		"spdx.py":  RuleLicense,
+		"old.sh":   RuleLicense,
+		"wrong.go": RuleLicense,
+		"ent.go":   RuleLicense,
+	}, paths(findings))
+
+	checker.Styles = []HeaderStyle{FullText, SPDX}
+	checker.SPDXEnterprise = "LicenseRef-Acme-Proprietary"
+	findings, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{
+		"old.sh":   RuleCopyrightYear,
+		"wrong.go": RuleLicense,
+	}, paths(findings))
+	for _, f := range findings {
+		if f.Path == "wrong.go" {
+			assert.Equal(t, osHeader("//", 2020)[len("// Copyright 2020 Northern.tech AS\n//\n"):],
+				f.Expected+"\n")
+		}
+	}
+
+	// The fixer converts full text headers to SPDX, keeping the years.
+	checker.Styles = []HeaderStyle{SPDX}
+	changes, err := checker.Fix(context.Background(), false)
+	require.NoError(t, err)
+	fixed := map[string]string{}
+	for _, change := range changes {
+		fixed[change.Finding.Path] = change.New
+	}
+	assert.Equal(t, map[string]string{
+		"full.go": "// Copyright 2020 Northern.tech AS\n" +
+			"// SPDX-License-Identifier: Apache-2.0\n\npackage full\n",
+		"old.sh": "#!/bin/sh\n# Copyright 2018-2020 Northern.tech AS\n" +
+			"# SPDX-License-Identifier: Apache-2.0\n",
+		"wrong.go": "// Copyright 2020 Northern.tech AS\n" +
+			"// SPDX-License-Identifier: Apache-2.0\n",
+	}, fixed)
+	findings, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	// And back.
+	checker.Styles = []HeaderStyle{FullText}
+	_, err = checker.Fix(context.Background(), false)
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(r.dir, "spdx.py"))
+	require.NoError(t, err)
+	assert.Equal(t, osHeader("#", 2020)+"\nimport os\n", string(content))
+	content, err = os.ReadFile(filepath.Join(r.dir, "ent.go"))
+	require.NoError(t, err)
+	assert.Equal(t, entHeader("//", 2020)+"\npackage ent\n", string(content))
+
+	checker.Styles = []HeaderStyle{"short"}
+	_, err = checker.Check(context.Background())
+	assert.EqualError(t, err, `unknown header style "short"`)
+}
+
+func TestCheckerGenerated(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("a.pb.go", "// Code generated by protoc-gen-go. DO NOT EDIT.\npackage a\n")
+	r.write("mock.go", "//go:build mock\n\n"+osHeader("//", 2019)+
+		"\n// Code generated by mockery v2.20.0. DO NOT EDIT.\n\npackage a\n")
+	r.write("gen.py", "# @generated\nimport os\n")
+	r.write("thrift.py", "#\n# Autogenerated by Thrift\n#\nimport os\n")
+	r.write("example.go", osHeader("//", 2020)+
+		"\n// Example output:\n//\n//\t// Code generated by x. DO NOT EDIT.\npackage a\n")
+	r.write("block.c", "/*\n * @generated by a tool\n */\nint a;\n")
+	// Markers after the code starts don't count.
+	r.write("late.go", "package a\n\n// Code generated by hand. DO NOT EDIT.\n")
+	r.write("late.py", "import os\n# @generated\n")
+	r.commitAt("2020-06-01T12:00:00", "Initial commit")
+
+	checker := &Checker{Root: r.dir}
+	findings, err := checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{
+		"thrift.py": RuleLicense,
+		"late.go":   RuleLicense,
+		"late.py":   RuleLicense,
+	}, paths(findings))
+
+	checker.GeneratedMarkers = []*regexp.Regexp{regexp.MustCompile(`^# Autogenerated by Thrift`)}
+	findings, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{"late.go": RuleLicense, "late.py": RuleLicense},
+		paths(findings))
+	r.git("rm", "-q", "late.go", "late.py")
+	r.commitAt("2020-06-01T12:00:00", "Remove late files")
+
+	// Generated files are checked against the generator's header, but not
+	// their copyright year, and aren't fixed.
+	generator, err := ParseTemplate(
+		"Copyright {year} {holder}\n\nGenerated from Apache-2.0 sources.\n")
+	require.NoError(t, err)
+	checker.Generated = CheckGenerated
+	checker.GeneratedOpenSource = generator
+	r.write("gen.py", "# Copyright 2015 Northern.tech AS\n#\n# Generated from Apache-2.0 sources.\n"+
+		"# @generated\nimport os\n")
+	findings, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{
+		"a.pb.go":   RuleLicense,
+		"block.c":   RuleLicense,
+		"mock.go":   RuleLicense,
+		"thrift.py": RuleLicense,
+	}, paths(findings))
+	for _, f := range findings {
+		assert.True(t, f.Generated, f.Path)
+		assert.Equal(t, "Generated from Apache-2.0 sources.", strings.TrimLeft(
+			strings.SplitN(f.Expected, "\n", 2)[0], "/# "))
+	}
+	changes, err := checker.Fix(context.Background(), true)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	checker.Generated = "ignore"
+	_, err = checker.Check(context.Background())
+	assert.EqualError(t, err, `unknown rule for generated files "ignore"`)
+}
+
+func TestCheckerChangedSince(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("old.go", "package a\n")
+	r.write("renamed.go", "package a\n")
+	r.write("templates/header.txt", "Copyright {year} {holder}\n")
+	base := r.commitAt("2020-06-01T12:00:00", "Initial commit")
+
+	r.write("added.go", "package a\n")
+	r.git("mv", "renamed.go", "moved.go")
+	r.commitAt("2020-06-02T12:00:00", "Add files")
+	r.write("untracked.go", "package a\n")
+
+	checker := &Checker{Root: r.dir, ChangedSince: base}
+	findings, err := checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{
+		"added.go":     RuleLicense,
+		"moved.go":     RuleLicense,
+		"untracked.go": RuleLicense,
+	}, paths(findings))
+
+	// Modified files are checked as well.
+	r.write("old.go", "package a\n\nfunc f() {}\n")
+	findings, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, paths(findings), "old.go")
+
+	// A change to the policy checks all files.
+	r.git("checkout", "-q", "--", "old.go")
+	r.write("templates/header.txt", "Copyright {year} {holder}\n\nAll rights reserved.\n")
+	checker.PolicyFiles = []string{"templates/header.txt"}
+	files, err := checker.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"added.go", "moved.go", "old.go", "untracked.go"}, files)
+
+	r.git("checkout", "-q", "--", "templates/header.txt")
+	r.write(IgnoreFile, "untracked.go\n")
+	files, err = checker.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"added.go", "moved.go", "old.go"}, files)
+
+	// So does an Enterprise fork point after the revision.
+	require.NoError(t, os.Remove(filepath.Join(r.dir, IgnoreFile)))
+	checker.FirstEnterpriseCommit = r.git("rev-parse", "HEAD")
+	files, err = checker.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"added.go", "moved.go", "old.go", "untracked.go"}, files)
+
+	checker.FirstEnterpriseCommit = base
+	files, err = checker.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"added.go", "moved.go", "untracked.go"}, files)
+
+	checker.ChangedSince = "no-such-revision"
+	_, err = checker.Files()
+	assert.Error(t, err)
+}
+
+func TestHistory(t *testing.T) {
+	r := newTestRepo(t)
+	hist, err := newHistory(context.Background(), r.dir, "")
+	require.NoError(t, err)
+	assert.Equal(t, time.Now().Year(), hist.addedYear("a.go"))
+
+	r.write("a.go", "package a\n")
+	r.write("b.go", "package a\n")
+	r.write("dir/c.go", "package c\n")
+	r.commitAt("2018-06-01T12:00:00", "Initial commit")
+	r.git("mv", "a.go", "moved.go")
+	r.write("été.go", "package a\n")
+	r.commitAt("2019-06-01T12:00:00", "Move a.go")
+	r.git("rm", "-q", "b.go")
+	r.commitAt("2020-06-01T12:00:00", "Remove b.go")
+	r.write("b.go", "package b\n")
+	entCommit := r.commitAt("2021-06-01T12:00:00", "Add b.go again")
+
+	hist, err = newHistory(context.Background(), r.dir, entCommit)
+	require.NoError(t, err)
+	assert.Equal(t, 2018, hist.addedYear("moved.go"))
+	assert.Equal(t, 2019, hist.addedYear("été.go"))
+	assert.Equal(t, 2021, hist.addedYear("b.go"))
+	assert.Equal(t, 2018, hist.addedYear("dir/c.go"))
+	assert.False(t, hist.isEnterprise("moved.go"))
+	assert.False(t, hist.isEnterprise("dir/c.go"))
+	assert.True(t, hist.isEnterprise("b.go"))
+
+	// Paths are relative to the root.
+	hist, err = newHistory(context.Background(), filepath.Join(r.dir, "dir"), entCommit)
+	require.NoError(t, err)
+	assert.Equal(t, 2018, hist.addedYear("c.go"))
+	assert.False(t, hist.isEnterprise("c.go"))
+}
+
+func TestHistoryLatestAdd(t *testing.T) {
+	r := newTestRepo(t)
+	r.write("a.go", "package a\n")
+	r.commitAt("2020-06-01T12:00:00", "Add a.go")
+	r.git("rm", "-q", "a.go")
+	r.commitAt("2021-06-01T12:00:00", "Remove a.go")
+	// Re-added with an older author date, like a cherry-pick.
+	r.write("a.go", "package a\n")
+	r.git("add", "a.go")
+	r.gitAt("2022-06-01T12:00:00", "commit", "-q", "-m", "Add a.go again",
+		"--date=2019-06-01T12:00:00")
+
+	r.git("checkout", "-q", "-b", "side")
+	r.write("side.go", "package a\n")
+	r.commitAt("2020-06-01T12:00:00", "Add side.go")
+	r.git("checkout", "-q", "master")
+	r.write("master.go", "package a\n")
+	r.commitAt("2021-06-01T12:00:00", "Add master.go")
+	r.git("merge", "-q", "--no-commit", "side")
+	// Added by the merge commit itself, in neither parent.
+	r.write("merge.go", "package a\n")
+	r.commitAt("2022-06-01T12:00:00", "Merge side")
+
+	hist, err := newHistory(context.Background(), r.dir, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2020, hist.addedYear("a.go"))
+	assert.Equal(t, 2020, hist.addedYear("side.go"))
+	assert.Equal(t, 2021, hist.addedYear("master.go"))
+	assert.Equal(t, 2022, hist.addedYear("merge.go"))
+}
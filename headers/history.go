@@ -0,0 +1,189 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package headers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mendersoftware/mendertesting/internal/git"
+)
+
+// history answers the questions the header check has about the git history
+// of the files. It reads the history once, instead of running git for every
+// file.
+type history struct {
+	// addedYears are the latest years in which the files were added to
+	// git, following renames.
+	addedYears map[string]int
+	// openSource are the files of the latest commit that is not a
+	// descendant of the first Enterprise commit. Nil if this isn't an
+	// Enterprise repository.
+	openSource map[string]bool
+	// allEnterprise is set if there is no Open Source commit at all.
+	allEnterprise bool
+}
+
+func newHistory(ctx context.Context, root, entCommit string) (*history, error) {
+	addedYears, err := readAddedYears(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	h := &history{addedYears: addedYears}
+	if entCommit == "" {
+		return h, nil
+	}
+	latestOpenSource, err := latestOpenSourceCommit(ctx, root, entCommit)
+	if err != nil {
+		return nil, err
+	}
+	if latestOpenSource == "" {
+		h.allEnterprise = true
+		return h, nil
+	}
+	h.openSource, err = readTree(ctx, root, latestOpenSource)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// latestOpenSourceCommit returns the latest commit that is not a descendant
+// of the first Enterprise commit, or "" if there is none.
+func latestOpenSourceCommit(ctx context.Context, root, entCommit string) (string, error) {
+	entCommit, err := git.Output(ctx, root, "rev-parse", "--verify", entCommit+"^{commit}")
+	if err != nil {
+		return "", err
+	}
+	entCommit = strings.TrimSpace(entCommit)
+
+	// The boundary commits of the ancestry path are the Open Source commits
+	// merged into Enterprise, the first one in date order is the latest.
+	revs, err := git.Lines(ctx, root, "rev-list", entCommit+"..HEAD",
+		"--ancestry-path", "--boundary", "--date-order")
+	if err != nil {
+		return "", err
+	}
+	for _, rev := range revs {
+		if strings.HasPrefix(rev, "-") && !strings.Contains(rev, entCommit) {
+			return strings.TrimPrefix(rev, "-"), nil
+		}
+	}
+
+	// This can happen if Open Source has never been merged into the repo
+	// after the fork, or if the Enterprise commit was pushed directly
+	// instead of being merged. Then the parent is the correct commit.
+	parent, err := git.Output(ctx, root, "rev-parse", "--verify", "--quiet", entCommit+"~1")
+	if err != nil {
+		// There is no commit before the Enterprise commit, all code is
+		// Enterprise.
+		return "", nil
+	}
+	return strings.TrimSpace(parent), nil
+}
+
+// readTree returns the paths of all files in the commit, relative to root.
+func readTree(ctx context.Context, root, commit string) (map[string]bool, error) {
+	output, err := git.Output(ctx, root, "ls-tree", "-r", "-z", "--name-only", commit)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]bool)
+	for _, name := range strings.Split(output, "\x00") {
+		if name != "" {
+			files[name] = true
+		}
+	}
+	return files, nil
+}
+
+// readAddedYears walks the history from the oldest commit to HEAD, and
+// returns the latest year in which every file was added, with the years of
+// renamed files carried over to their new paths.
+func readAddedYears(ctx context.Context, root string) (map[string]int, error) {
+	years := make(map[string]int)
+	if !git.Succeeds(ctx, root, "rev-parse", "--verify", "--quiet", "HEAD") {
+		// Nothing is committed yet.
+		return years, nil
+	}
+	// The combined diff of a merge commit only lists the files which differ
+	// from all parents, so the files added by the merge itself.
+	lines, err := git.Lines(ctx, root, "log", "--reverse", "--topo-order",
+		"--format=%ad", "--date=format:%Y", "--name-status", "-M", "-c", "--relative", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	year := 0
+	for _, line := range lines {
+		// Lines without a tab are the dates of the commits, the others
+		// the changed files: "<status>\t<path>", with one status letter
+		// per parent in merge commits, and "R<score>\t<old>\t<new>" for
+		// renames.
+		fields := strings.Split(line, "\t")
+		if len(fields) == 1 {
+			year, _ = strconv.Atoi(strings.TrimSpace(line))
+			continue
+		}
+		for i := range fields[1:] {
+			fields[i+1] = unquotePath(fields[i+1])
+		}
+		switch {
+		case strings.Trim(fields[0], "A") == "":
+			setLatest(years, fields[1], year)
+		case strings.HasPrefix(fields[0], "R") && len(fields) == 3:
+			if added, ok := years[fields[1]]; ok {
+				setLatest(years, fields[2], added)
+			}
+		}
+	}
+	return years, nil
+}
+
+// setLatest sets the year of a file, unless it already has a later one.
+func setLatest(years map[string]int, name string, year int) {
+	if year > years[name] {
+		years[name] = year
+	}
+}
+
+// unquotePath returns a path as printed by git, which quotes paths with
+// special characters like C strings.
+func unquotePath(name string) string {
+	if !strings.HasPrefix(name, `"`) {
+		return name
+	}
+	if unquoted, err := strconv.Unquote(name); err == nil {
+		return unquoted
+	}
+	return name
+}
+
+// isEnterprise reports whether the file is Enterprise code, meaning that it
+// doesn't exist in the latest Open Source commit.
+func (h *history) isEnterprise(name string) bool {
+	return h.allEnterprise || (h.openSource != nil && !h.openSource[name])
+}
+
+// addedYear returns the latest year in which the file was added to git,
+// following renames. Files that aren't committed yet are about to be added,
+// so they get the current year.
+func (h *history) addedYear(name string) int {
+	if added, ok := h.addedYears[name]; ok {
+		return added
+	}
+	return time.Now().Year()
+}
@@ -0,0 +1,94 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package headers
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Class is the license class of a source file.
+type Class int
+
+const (
+	// OpenSource files are licensed under the Apache License 2.0.
+	OpenSource Class = iota
+	// Enterprise files are proprietary.
+	Enterprise
+)
+
+func (c Class) String() string {
+	if c == Enterprise {
+		return "Enterprise"
+	}
+	return "Open Source"
+}
+
+var openSourceLicense = []string{
+	`    Licensed under the Apache License, Version 2.0 (the "License");`,
+	`    you may not use this file except in compliance with the License.`,
+	`    You may obtain a copy of the License at`,
+	``,
+	`        http://www.apache.org/licenses/LICENSE-2.0`,
+	``,
+	`    Unless required by applicable law or agreed to in writing, software`,
+	`    distributed under the License is distributed on an "AS IS" BASIS,`,
+	`    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.`,
+	`    See the License for the specific language governing permissions and`,
+	`    limitations under the License.`,
+}
+
+var enterpriseLicense = []string{
+	`    All Rights Reserved`,
+}
+
+var (
+	// encodingCookieRegexp is the Python source encoding declaration of
+	// PEP 263.
+	encodingCookieRegexp  = regexp.MustCompile(`^[ \t\f]*#.*?coding[:=][ \t]*[-\w.]+`)
+	buildConstraintRegexp = regexp.MustCompile(`^//(go:build|\s*\+build)\s`)
+	// dockerDirectiveRegexp is a Dockerfile parser directive.
+	dockerDirectiveRegexp = regexp.MustCompile(`(?i)^#\s*(syntax|escape|check)\s*=`)
+)
+
+// preamble returns the number of leading lines which must stay in front of
+// the license header: a "#!" line, a Python or Ruby encoding cookie, which
+// only works on the first two lines, Go build constraints, together with the
+// empty lines after them, and Dockerfile parser directives.
+func preamble(fileType string, lines []string) int {
+	n := 0
+	if len(lines) > 0 && strings.HasPrefix(strings.TrimLeft(lines[0], " \t"), "#!") {
+		n = 1
+	}
+	switch fileType {
+	case "python", "ruby":
+		if n < len(lines) && encodingCookieRegexp.MatchString(lines[n]) {
+			n++
+		}
+	case "dockerfile":
+		for n < len(lines) && dockerDirectiveRegexp.MatchString(lines[n]) {
+			n++
+		}
+	case "go":
+		start := n
+		for n < len(lines) && buildConstraintRegexp.MatchString(lines[n]) {
+			n++
+		}
+		for n > start && n < len(lines) && strings.TrimSpace(lines[n]) == "" {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package headers
+
+import "fmt"
+
+// HeaderStyle is a form of license header.
+type HeaderStyle string
+
+const (
+	// FullText headers carry the license text of the template:
+	//
+	//	// Copyright 2024 Northern.tech AS
+	//	//
+	//	//    Licensed under the Apache License, Version 2.0 (the "License");
+	//	...
+	FullText HeaderStyle = "full"
+	// SPDX headers carry an SPDX license identifier instead of the text:
+	//
+	//	// Copyright 2024 Northern.tech AS
+	//	// SPDX-License-Identifier: Apache-2.0
+	SPDX HeaderStyle = "spdx"
+)
+
+// The SPDX license identifiers of the license classes, if not configured.
+// Enterprise files are proprietary, which SPDX expresses with a LicenseRef.
+const (
+	DefaultSPDXOpenSource = "Apache-2.0"
+	DefaultSPDXEnterprise = "LicenseRef-Northern.tech-Enterprise"
+)
+
+// styles returns the accepted header styles, or an error for an unknown
+// one.
+func (c *Checker) styles() ([]HeaderStyle, error) {
+	if len(c.Styles) == 0 {
+		return []HeaderStyle{FullText}, nil
+	}
+	for _, style := range c.Styles {
+		if style != FullText && style != SPDX {
+			return nil, fmt.Errorf("unknown header style %q", style)
+		}
+	}
+	return c.Styles, nil
+}
+
+// spdxTemplate returns the template of an SPDX header with the identifier.
+func spdxTemplate(identifier string) *Template {
+	return &Template{lines: []string{
+		"Copyright " + YearPlaceholder + " " + HolderPlaceholder,
+		"SPDX-License-Identifier: " + identifier,
+	}}
+}
+
+// styleTemplate returns the default template of the license class in the
+// header style.
+func (c *Checker) styleTemplate(style HeaderStyle, class Class) *Template {
+	if style != SPDX {
+		return DefaultTemplate(class)
+	}
+	identifier, fallback := c.SPDXOpenSource, DefaultSPDXOpenSource
+	if class == Enterprise {
+		identifier, fallback = c.SPDXEnterprise, DefaultSPDXEnterprise
+	}
+	if identifier == "" {
+		identifier = fallback
+	}
+	return spdxTemplate(identifier)
+}
@@ -0,0 +1,272 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package headers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The placeholders of a Template.
+const (
+	YearPlaceholder   = "{year}"
+	HolderPlaceholder = "{holder}"
+)
+
+// Template is a license header without comment markers. Its first line is
+// the copyright line, which must contain YearPlaceholder, and
+// HolderPlaceholder may appear on any line. In a source file, every line is
+// commented out with the prefix of the file's comment style and a space, or
+// just the prefix if the line is empty.
+type Template struct {
+	lines []string
+}
+
+// ParseTemplate parses the text of a header template.
+func ParseTemplate(text string) (*Template, error) {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("empty header template")
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+		count := strings.Count(line, YearPlaceholder)
+		if i == 0 && count != 1 {
+			return nil, fmt.Errorf("the first line of a header template must contain %s once",
+				YearPlaceholder)
+		} else if i > 0 && count > 0 {
+			return nil, fmt.Errorf("line %d: %s is only allowed in the first line",
+				i+1, YearPlaceholder)
+		}
+	}
+	return &Template{lines: lines}, nil
+}
+
+// ReadTemplate reads a header template from a file.
+func ReadTemplate(name string) (*Template, error) {
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	t, err := ParseTemplate(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return t, nil
+}
+
+var defaultTemplates = map[Class]*Template{
+	OpenSource: licenseTemplate(openSourceLicense),
+	Enterprise: licenseTemplate(enterpriseLicense),
+}
+
+func licenseTemplate(license []string) *Template {
+	lines := []string{"Copyright " + YearPlaceholder + " " + HolderPlaceholder, ""}
+	for _, line := range license {
+		lines = append(lines, strings.TrimPrefix(line, " "))
+	}
+	return &Template{lines: lines}
+}
+
+// DefaultTemplate returns the header template of Mender source files of the
+// license class.
+func DefaultTemplate(class Class) *Template {
+	return defaultTemplates[class]
+}
+
+// String returns the text of the template.
+func (t *Template) String() string {
+	return strings.Join(t.lines, "\n") + "\n"
+}
+
+func commentLine(style CommentStyle, text string) string {
+	if text == "" {
+		return style.Prefix
+	}
+	return style.Prefix + " " + text
+}
+
+// body returns the commented lines which must follow the copyright line.
+func (t *Template) body(style CommentStyle, holder string) []string {
+	lines := make([]string, 0, len(t.lines)-1)
+	for _, line := range t.lines[1:] {
+		line = strings.ReplaceAll(line, HolderPlaceholder, holder)
+		lines = append(lines, commentLine(style, line))
+	}
+	return lines
+}
+
+// header returns all lines of the header, including the lines opening and
+// closing a block comment. The years may be a single year, a range or a
+// list, and the other copyright lines, without comment prefix, come before
+// the copyright line of the holder.
+func (t *Template) header(style CommentStyle, years, holder string, others []string) []string {
+	var lines []string
+	if style.Open != "" {
+		lines = append(lines, style.Open)
+	}
+	for _, other := range others {
+		lines = append(lines, commentLine(style, other))
+	}
+	copyright := strings.ReplaceAll(t.lines[0], HolderPlaceholder, holder)
+	copyright = strings.Replace(copyright, YearPlaceholder, years, 1)
+	lines = append(lines, commentLine(style, copyright))
+	lines = append(lines, t.body(style, holder)...)
+	if style.Close != "" {
+		lines = append(lines, style.Close)
+	}
+	return lines
+}
+
+// yearsPattern matches a year, a range of years like "2019-2024", or a
+// comma separated list of them.
+const yearsPattern = `\d{4}(?:\s*-\s*\d{4})?(?:\s*,\s*\d{4}(?:\s*-\s*\d{4})?)*`
+
+// anyHolder is the holder pattern of copyrightRegexp for copyright lines of
+// any holder.
+const anyHolder = `\S.*?`
+
+// copyrightRegexp matches the copyright line of the template, after the
+// comment prefix and the space, and captures the years. The holder is a
+// regular expression, like regexp.QuoteMeta of a holder, or anyHolder.
+func (t *Template) copyrightRegexp(holder string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i, part := range strings.Split(t.lines[0], HolderPlaceholder) {
+		if i > 0 {
+			b.WriteString(holder)
+		}
+		b.WriteString(strings.Replace(regexp.QuoteMeta(part),
+			regexp.QuoteMeta(YearPlaceholder), "("+yearsPattern+")", 1))
+	}
+	return regexp.MustCompile(b.String())
+}
+
+var yearRegexp = regexp.MustCompile(`\d{4}`)
+
+// latestYear returns the latest year of a match of yearsPattern.
+func latestYear(years string) int {
+	latest := 0
+	for _, year := range yearRegexp.FindAllString(years, -1) {
+		if y, _ := strconv.Atoi(year); y > latest {
+			latest = y
+		}
+	}
+	return latest
+}
+
+// copyrightLines returns the number of consecutive copyright lines, of any
+// holder, at the start of lines.
+func (t *Template) copyrightLines(lines []string, style CommentStyle) int {
+	copyright := t.copyrightRegexp(anyHolder)
+	n := 0
+	for n < len(lines) && copyright.MatchString(commentText(lines[n], style)) {
+		n++
+	}
+	return n
+}
+
+// commentText returns the text of a comment line after the prefix and the
+// space, or "" if it isn't one.
+func commentText(line string, style CommentStyle) string {
+	if !strings.HasPrefix(line, style.Prefix+" ") {
+		return ""
+	}
+	return line[len(style.Prefix)+1:]
+}
+
+// DirTemplate selects the header templates and the copyright holder of the
+// files below a directory.
+type DirTemplate struct {
+	// Dir is slash separated and relative to the root of the checked
+	// repository. An empty Dir applies to all files.
+	Dir string
+	// OpenSource and Enterprise are the templates of the license classes.
+	OpenSource *Template
+	Enterprise *Template
+	// CopyrightHolder replaces HolderPlaceholder in the templates.
+	CopyrightHolder string
+}
+
+func (d DirTemplate) contains(name string) bool {
+	dir := strings.Trim(path.Clean("/"+d.Dir), "/")
+	return dir == "" || name == dir || strings.HasPrefix(name, dir+"/")
+}
+
+func (d DirTemplate) depth() int {
+	dir := strings.Trim(path.Clean("/"+d.Dir), "/")
+	if dir == "" {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
+
+// expectedHeader is the header a file is expected to carry.
+type expectedHeader struct {
+	// template is used to fix headers, and the alternatives are accepted
+	// as well.
+	template     *Template
+	alternatives []*Template
+	holder       string
+}
+
+// match returns the template of the header at the start of the lines, or
+// nil if none matches.
+func (h expectedHeader) match(lines []string, style CommentStyle) *Template {
+	for _, t := range append([]*Template{h.template}, h.alternatives...) {
+		if headerMatches(lines, style, t, t.body(style, h.holder)) {
+			return t
+		}
+	}
+	return nil
+}
+
+// headerFor returns the expected header of the file in the license class.
+// The settings of the most specific directory apply, and those it doesn't
+// set are taken from less specific ones, and finally the defaults.
+func (c *Checker) headerFor(name string, class Class, styles []HeaderStyle) expectedHeader {
+	h := expectedHeader{template: c.styleTemplate(styles[0], class), holder: c.holder()}
+	for _, style := range styles[1:] {
+		h.alternatives = append(h.alternatives, c.styleTemplate(style, class))
+	}
+	dirs := make([]DirTemplate, 0, len(c.Templates))
+	for _, d := range c.Templates {
+		if d.contains(name) {
+			dirs = append(dirs, d)
+		}
+	}
+	sort.SliceStable(dirs, func(i, j int) bool { return dirs[i].depth() < dirs[j].depth() })
+	for _, d := range dirs {
+		template := d.OpenSource
+		if class == Enterprise {
+			template = d.Enterprise
+		}
+		if template != nil {
+			h.template = template
+			h.alternatives = nil
+		}
+		if d.CopyrightHolder != "" {
+			h.holder = d.CopyrightHolder
+		}
+	}
+	return h
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package diff formats differences between texts.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around a change.
+const diffContext = 3
+
+// Unified returns a unified diff between old and new with a single hunk
+// spanning all changed lines, which is all a header fix needs, since it
+// only touches the top of a file, and short texts like licenses.
+func Unified(name, old, new string) string {
+	a := splitLines(old)
+	b := splitLines(new)
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	if prefix == len(a) && prefix == len(b) {
+		return ""
+	}
+
+	start := prefix - diffContext
+	if start < 0 {
+		start = 0
+	}
+	endA := len(a) - suffix + diffContext
+	if endA > len(a) {
+		endA = len(a)
+	}
+	endB := endA - len(a) + len(b)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- a/%s\n+++ b/%s\n", name, name)
+	fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(start, endA), hunkRange(start, endB))
+	for _, line := range a[start:prefix] {
+		writeDiffLine(&out, ' ', line)
+	}
+	for _, line := range a[prefix : len(a)-suffix] {
+		writeDiffLine(&out, '-', line)
+	}
+	for _, line := range b[prefix : len(b)-suffix] {
+		writeDiffLine(&out, '+', line)
+	}
+	for _, line := range a[len(a)-suffix : endA] {
+		writeDiffLine(&out, ' ', line)
+	}
+	return out.String()
+}
+
+// splitLines splits text into lines, keeping the line endings.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// hunkRange formats the 0-based, half open range [start, end) as a hunk
+// range.
+func hunkRange(start, end int) string {
+	if end == start {
+		// An empty range refers to the line before it.
+		return fmt.Sprintf("%d,0", start)
+	}
+	if end-start == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, end-start)
+}
+
+func writeDiffLine(out *strings.Builder, op byte, line string) {
+	out.WriteByte(op)
+	out.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		out.WriteString("\n\\ No newline at end of file\n")
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package ignore matches paths against patterns in the gitignore(5) format.
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+)
+
+type rule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// Matcher is a list of patterns, where the last matching pattern decides.
+// The zero value matches nothing.
+type Matcher struct {
+	rules []rule
+}
+
+// ReadFile reads the patterns in the file. A missing file has no patterns.
+func ReadFile(name string) (*Matcher, error) {
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Matcher{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return m, nil
+}
+
+// Parse reads patterns, one per line, relative to the root of the paths
+// given to Match.
+func Parse(r io.Reader) (*Matcher, error) {
+	m := &Matcher{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		pattern := trimTrailingSpace(strings.TrimSuffix(scanner.Text(), "\r"))
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		var rl rule
+		if strings.HasPrefix(pattern, "!") {
+			rl.negate = true
+			pattern = pattern[1:]
+		}
+		if strings.HasSuffix(pattern, "/") {
+			rl.dirOnly = true
+			pattern = strings.TrimRight(pattern, "/")
+		}
+		if pattern == "" {
+			continue
+		}
+		re, err := compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rl.re = re
+		m.rules = append(m.rules, rl)
+	}
+	return m, scanner.Err()
+}
+
+// trimTrailingSpace removes trailing spaces, unless they are escaped.
+func trimTrailingSpace(pattern string) string {
+	for strings.HasSuffix(pattern, " ") && !strings.HasSuffix(pattern, `\ `) {
+		pattern = pattern[:len(pattern)-1]
+	}
+	return pattern
+}
+
+// compile translates a pattern to a regular expression. A pattern with a
+// slash, other than at the end, is relative to the root; otherwise it
+// matches at any level.
+func compile(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	if strings.Contains(pattern, "/") {
+		pattern = strings.TrimPrefix(pattern, "/")
+	} else {
+		b.WriteString("(?:.*/)?")
+	}
+	segments := strings.Split(pattern, "/")
+	last := len(segments) - 1
+	for i, segment := range segments {
+		if segment == "**" {
+			if i == last {
+				b.WriteString(".*")
+			} else {
+				b.WriteString("(?:.*/)?")
+			}
+			continue
+		}
+		if err := writeGlob(&b, segment); err != nil {
+			return nil, err
+		}
+		if i != last {
+			b.WriteString("/")
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// writeGlob writes the regular expression of a glob matching within a single
+// path segment.
+func writeGlob(b *strings.Builder, glob string) error {
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return fmt.Errorf("unterminated character class in %q", glob)
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return nil
+}
+
+// Match reports whether the slash separated path is ignored. Like in git, a
+// path is also ignored if any of its parent directories is, and can't be
+// re-included by a negated pattern then.
+func (m *Matcher) Match(name string, isDir bool) bool {
+	parts := strings.Split(name, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.match(name, isDir)
+}
+
+func (m *Matcher) match(name string, isDir bool) bool {
+	ignored := false
+	for _, rl := range m.rules {
+		if rl.dirOnly && !isDir {
+			continue
+		}
+		if rl.re.MatchString(name) {
+			ignored = !rl.negate
+		}
+	}
+	return ignored
+}
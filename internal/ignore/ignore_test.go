@@ -0,0 +1,116 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ignore
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const patterns = `# Generated code
+*.pb.go
+!keep.pb.go
+/root-only.go
+docs/*.py
+build/
+**/mocks/**
+a/**/z.sh
+\#hash.c
+` + "trailing.c   \n" + `file[0-9].h
+file[!0-9]x.h
+`
+
+// files maps paths to whether they are ignored by the patterns.
+var files = map[string]bool{
+	"x.pb.go":            true,
+	"sub/x.pb.go":        true,
+	"sub/keep.pb.go":     false,
+	"root-only.go":       true,
+	"sub/root-only.go":   false,
+	"docs/a.py":          true,
+	"docs/sub/a.py":      false,
+	"sub/docs/a.py":      false,
+	"build/a.go":         true,
+	"sub/build/x/b.go":   true,
+	"build.go":           false,
+	"x/mocks/m.go":       true,
+	"mocks/deep/m.go":    true,
+	"mocks.go":           false,
+	"a/z.sh":             true,
+	"a/b/c/z.sh":         true,
+	"b/a/z.sh":           false,
+	"#hash.c":            true,
+	"trailing.c":         true,
+	"file1.h":            true,
+	"filex.h":            false,
+	"filexx.h":           true,
+	"file1x.h":           false,
+	"build/keep.pb.go":   true,
+	"regular/source.go":  false,
+	"regular/source.txt": false,
+}
+
+func TestMatcher(t *testing.T) {
+	m, err := Parse(strings.NewReader(patterns))
+	require.NoError(t, err)
+	for name, ignored := range files {
+		assert.Equal(t, ignored, m.Match(name, false), name)
+	}
+	assert.True(t, m.Match("build", true))
+	assert.False(t, m.Match("build", false))
+
+	_, err = Parse(strings.NewReader("file[0-9.h\n"))
+	assert.EqualError(t, err, `line 1: unterminated character class in "file[0-9.h"`)
+
+	m, err = ReadFile(filepath.Join(t.TempDir(), "nonexistent"))
+	require.NoError(t, err)
+	assert.False(t, m.Match("a.go", false))
+}
+
+// TestMatcherLikeGit checks that git agrees with the expected results.
+func TestMatcherLikeGit(t *testing.T) {
+	dir := t.TempDir()
+	var expected []string
+	for name, ignored := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, nil, 0644))
+		if ignored {
+			expected = append(expected, name)
+		}
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".licenseignore"), []byte(patterns),
+		0644))
+	cmd := exec.Command("git", "init", "-q")
+	cmd.Dir = dir
+	require.NoError(t, cmd.Run())
+
+	cmd = exec.Command("git", "ls-files", "--others", "--ignored",
+		"--exclude-from=.licenseignore")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	require.NoError(t, err)
+	actual := strings.Fields(string(output))
+	sort.Strings(expected)
+	sort.Strings(actual)
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,407 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package mendertesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mendersoftware/mendertesting/commitlint"
+	"github.com/mendersoftware/mendertesting/commits"
+	"github.com/mendersoftware/mendertesting/config"
+	"github.com/mendersoftware/mendertesting/headers"
+	"github.com/mendersoftware/mendertesting/licenses"
+)
+
+// Options describes the compliance policy of a repository, on top of the
+// .mendertesting.yaml file of the repository and the environment, see
+// package config. The zero value checks the current working directory with
+// the policy of that file, or as a pure Open Source repository if there is
+// none.
+type Options struct {
+	// KnownLicenseFiles lists license files for dependencies which should
+	// be accepted even if they don't follow the common license file names.
+	KnownLicenseFiles []string
+
+	// FirstEnterpriseCommit is the oldest commit that is not part of Open
+	// Source, only part of Enterprise, if any. IOW it should be the very
+	// first commit after the fork point, on the Enterprise branch. It
+	// overrides FIRST_ENT_COMMIT.
+	FirstEnterpriseCommit string
+
+	// IgnorePatterns are find(1) regular expressions, matched against
+	// "./<path>", of files excluded from the source header check. They
+	// are combined with LICENSE_HEADERS_IGNORE_FILES_REGEXP and the
+	// patterns in the .licenseignore file of the repository.
+	IgnorePatterns []string
+
+	// HeaderFileTypes are the file types, besides the default ones, whose
+	// license headers are checked, see headers.FileTypes. They are combined
+	// with LICENSE_HEADERS_FILE_TYPES.
+	HeaderFileTypes []string
+
+	// RepoRoot is the root of the repository to check. Defaults to the
+	// current working directory.
+	RepoRoot string
+
+	// LegacyCommitLint lints commit messages with the strict rules of the
+	// gawk commitlint, instead of grammar.md.
+	LegacyCommitLint bool
+
+	// ChangedSince limits the checks to the changes since this revision:
+	// only files added, renamed or modified since then are checked for
+	// license headers, see headers.Checker.ChangedSince, and only the
+	// commits in ChangedSince..HEAD are checked.
+	ChangedSince string
+}
+
+// copy returns a deep copy of the options, so that the result can be used
+// without holding on to slices owned by somebody else.
+func (o Options) copy() Options {
+	o.KnownLicenseFiles = append([]string(nil), o.KnownLicenseFiles...)
+	o.IgnorePatterns = append([]string(nil), o.IgnorePatterns...)
+	o.HeaderFileTypes = append([]string(nil), o.HeaderFileTypes...)
+	return o
+}
+
+var (
+	defaultOptionsLock sync.Mutex
+	defaultOptions     Options
+)
+
+// Specify a license file for a dependency explicitly, avoiding the check for
+// common license file names. This modifies the options used by
+// CheckMenderCompliance. The file can also be listed under
+// licenses.known_license_files in .mendertesting.yaml.
+func SetLicenseFileForDependency(license_file string) {
+	defaultOptionsLock.Lock()
+	defer defaultOptionsLock.Unlock()
+	defaultOptions.KnownLicenseFiles = append(defaultOptions.KnownLicenseFiles, license_file)
+}
+
+// This should be set to the oldest commit that is not part of Open Source, only
+// part of Enterprise, if any. IOW it should be the very first commit after the
+// fork point, on the Enterprise branch. This modifies the options used by
+// CheckMenderCompliance, and overrides first_enterprise_commit in
+// .mendertesting.yaml.
+func SetFirstEnterpriseCommit(sha string) {
+	defaultOptionsLock.Lock()
+	defer defaultOptionsLock.Unlock()
+	defaultOptions.FirstEnterpriseCommit = sha
+}
+
+// DefaultOptions returns a copy of the options modified by
+// SetLicenseFileForDependency and SetFirstEnterpriseCommit.
+func DefaultOptions() Options {
+	defaultOptionsLock.Lock()
+	defer defaultOptionsLock.Unlock()
+	return defaultOptions.copy()
+}
+
+// loadConfig returns the configuration of the repository, with the
+// environment and then the options applied on top.
+func loadConfig(opts Options) (*config.Config, error) {
+	cfg, err := config.Load(opts.RepoRoot)
+	if err != nil {
+		return nil, err
+	}
+	if opts.FirstEnterpriseCommit != "" {
+		cfg.FirstEnterpriseCommit = opts.FirstEnterpriseCommit
+	}
+	cfg.Licenses.KnownLicenseFiles = append(cfg.Licenses.KnownLicenseFiles,
+		opts.KnownLicenseFiles...)
+	cfg.Headers.FileTypes = append(cfg.Headers.FileTypes, opts.HeaderFileTypes...)
+	cfg.Commits.Legacy = cfg.Commits.Legacy || opts.LegacyCommitLint
+	return cfg, nil
+}
+
+// enabledChecks returns the checks enabled in the configuration.
+func enabledChecks(cfg *config.Config) ([]complianceCheck, error) {
+	for _, name := range cfg.Checks {
+		known := false
+		for _, check := range complianceChecks {
+			known = known || string(check.check) == name
+		}
+		if !known {
+			return nil, fmt.Errorf("%s: unknown check %q", config.FileName, name)
+		}
+	}
+	var enabled []complianceCheck
+	for _, check := range complianceChecks {
+		if cfg.Enabled(string(check.check)) {
+			enabled = append(enabled, check)
+		}
+	}
+	return enabled, nil
+}
+
+// Check names one of the compliance checks.
+type Check string
+
+const (
+	// CheckHeaders is the license header check of the source files.
+	CheckHeaders Check = "headers"
+	// CheckCommits is the sign-off and commit message check.
+	CheckCommits Check = "commits"
+	// CheckLicenses is the check of the licenses of the dependencies.
+	CheckLicenses Check = "licenses"
+	// CheckLicenseYear is the check of the copyright year in the
+	// top-level LICENSE.
+	CheckLicenseYear Check = "license-year"
+)
+
+type complianceCheck struct {
+	check Check
+	run   func(cfg *config.Config, opts Options) error
+}
+
+// complianceChecks are the checks run by CheckMenderCompliance, in order.
+var complianceChecks = []complianceCheck{
+	{CheckHeaders, checkSourceHeaders},
+	{CheckCommits, checkCommits},
+	{CheckLicenses, checkLicenses},
+	{CheckLicenseYear, checkLicenseYear},
+}
+
+// Finding is a single compliance problem.
+type Finding struct {
+	Check Check
+	// Path is the slash separated path of the file the finding is about,
+	// relative to the repository root, if any.
+	Path string
+	// Line is the 1-based line in Path, if any.
+	Line int
+	// Commit is the full SHA of the commit the finding is about, if any.
+	Commit string
+	// Rule is the ID of the rule which failed, as defined by the package
+	// implementing the check.
+	Rule    string
+	Message string
+}
+
+func (f Finding) String() string {
+	switch {
+	case f.Commit != "":
+		return fmt.Sprintf("Commit %s: %s", f.Commit, f.Message)
+	case f.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", f.Path, f.Line, f.Message)
+	case f.Path != "":
+		return fmt.Sprintf("%s: %s", f.Path, f.Message)
+	}
+	return f.Message
+}
+
+// MenderComplianceError is returned when the repository is not compliant.
+// Findings has every problem found, and Output the human readable report.
+type MenderComplianceError struct {
+	Output   string
+	Err      error
+	Findings []Finding
+}
+
+func (m *MenderComplianceError) Error() string {
+	return fmt.Sprintf("MenderCompliance failed with error: %s\nOutput: %s\n", m.Err, m.Output)
+}
+
+func (m *MenderComplianceError) Unwrap() error {
+	return m.Err
+}
+
+// FindingsOf returns the findings of the given check.
+func (m *MenderComplianceError) FindingsOf(check Check) []Finding {
+	var result []Finding
+	for _, f := range m.Findings {
+		if f.Check == check {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// checkMenderCompliance runs all enabled checks. The findings of the failing
+// checks are combined into one *MenderComplianceError, unless a check could
+// not be carried out at all, in which case that error is returned.
+func checkMenderCompliance(opts Options) error {
+	cfg, err := loadConfig(opts)
+	if err != nil {
+		return err
+	}
+	enabled, err := enabledChecks(cfg)
+	if err != nil {
+		return err
+	}
+	var failed []*MenderComplianceError
+	for _, check := range enabled {
+		err := check.run(cfg, opts)
+		var complianceErr *MenderComplianceError
+		if errors.As(err, &complianceErr) {
+			failed = append(failed, complianceErr)
+		} else if err != nil {
+			return err
+		}
+	}
+	switch len(failed) {
+	case 0:
+		return nil
+	case 1:
+		return failed[0]
+	}
+	combined := &MenderComplianceError{}
+	var messages []string
+	for _, err := range failed {
+		combined.Output += err.Output
+		combined.Findings = append(combined.Findings, err.Findings...)
+		messages = append(messages, err.Err.Error())
+	}
+	combined.Err = errors.New(strings.Join(messages, "; "))
+	return combined
+}
+
+// checkSourceHeaders checks the license headers of all source files.
+func checkSourceHeaders(cfg *config.Config, opts Options) error {
+	checker, err := cfg.HeadersChecker(opts.RepoRoot)
+	if err != nil {
+		return err
+	}
+	for _, pattern := range opts.IgnorePatterns {
+		re, err := headers.CompileFindRegexp(pattern)
+		if err != nil {
+			return err
+		}
+		checker.Ignore = append(checker.Ignore, re)
+	}
+	checker.ChangedSince = opts.ChangedSince
+
+	findings, err := checker.Check(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+	var output strings.Builder
+	result := make([]Finding, 0, len(findings))
+	for _, finding := range findings {
+		fmt.Fprintf(&output, "!!! FAILED license check on %s\n", finding)
+		result = append(result, Finding{
+			Check:   CheckHeaders,
+			Path:    finding.Path,
+			Rule:    string(finding.Rule),
+			Message: finding.Message,
+		})
+	}
+	return &MenderComplianceError{
+		Err:      fmt.Errorf("%d files failed the license header check", len(findings)),
+		Output:   output.String(),
+		Findings: result,
+	}
+}
+
+// checkCommits checks the commits in the range given by the CI environment,
+// like check_commits.sh.
+func checkCommits(cfg *config.Config, opts Options) error {
+	ctx := context.Background()
+	commitRange := opts.ChangedSince + "..HEAD"
+	if opts.ChangedSince == "" {
+		var err error
+		commitRange, err = commits.RangeFromEnv(ctx, opts.RepoRoot)
+		if err != nil {
+			return err
+		}
+	}
+	policy := commits.PolicyFromEnv()
+	policy.Schema = !cfg.Commits.Unversioned
+	policy.Lint = commits.LintWith(cfg.CommitlintPolicy())
+
+	violations, err := commits.Check(ctx, opts.RepoRoot, commitRange, policy)
+	if err != nil {
+		return err
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	var output strings.Builder
+	fmt.Fprintf(&output, "Checking range: %s:\n", commitRange)
+	schema := false
+	result := make([]Finding, 0, len(violations))
+	for _, violation := range violations {
+		fmt.Fprintln(&output, violation)
+		schema = schema || violation.Rule == commits.RuleSchema
+		result = append(result, Finding{
+			Check:   CheckCommits,
+			Commit:  violation.Commit,
+			Rule:    string(violation.Rule),
+			Message: violation.Message,
+		})
+	}
+	if schema {
+		fmt.Fprintf(&output, "See %s for more information\n", commitlint.GrammarURL)
+	}
+	return &MenderComplianceError{
+		Err:      fmt.Errorf("%d commit problems found", len(violations)),
+		Output:   output.String(),
+		Findings: result,
+	}
+}
+
+// checkLicenses checks the licenses of all dependencies.
+func checkLicenses(cfg *config.Config, opts Options) error {
+	checker := &licenses.Checker{
+		Root:              opts.RepoRoot,
+		KnownLicenseFiles: cfg.Licenses.KnownLicenseFiles,
+		Policy:            cfg.LicensePolicy(),
+	}
+	findings, err := checker.CheckDependencies()
+	if err != nil {
+		return err
+	}
+	return licenseError(CheckLicenses, findings)
+}
+
+// checkLicenseYear checks the top-level license.
+func checkLicenseYear(cfg *config.Config, opts Options) error {
+	checker := &licenses.Checker{Root: opts.RepoRoot, CopyrightHolder: cfg.CopyrightHolder}
+	findings, err := checker.CheckTopLevel(context.Background())
+	if err != nil {
+		return err
+	}
+	return licenseError(CheckLicenseYear, findings)
+}
+
+func licenseError(check Check, findings []licenses.Finding) error {
+	if len(findings) == 0 {
+		return nil
+	}
+	var output strings.Builder
+	result := make([]Finding, 0, len(findings))
+	for _, finding := range findings {
+		fmt.Fprintln(&output, finding)
+		result = append(result, Finding{
+			Check:   check,
+			Path:    finding.Path,
+			Line:    finding.Line,
+			Rule:    string(finding.Rule),
+			Message: finding.Message,
+		})
+	}
+	return &MenderComplianceError{
+		Err:      fmt.Errorf("%d license problems found", len(findings)),
+		Output:   output.String(),
+		Findings: result,
+	}
+}
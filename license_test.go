@@ -15,268 +15,170 @@
 package mendertesting
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"os/exec"
-	"path"
 	"path/filepath"
-	"testing"
-
-	"errors"
 	"strings"
+	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-)
-
-const packageLocation string = "github.com/mendersoftware/mendertesting"
-
-var known_license_files []string = []string{}
 
-// Specify a license file for a dependency explicitly, avoiding the check for
-// common license file names.
-func SetLicenseFileForDependency(license_file string) {
-	known_license_files = append(known_license_files, "--add-license="+license_file)
-}
-
-var firstEnterpriseCommit = ""
-
-// This should be set to the oldest commit that is not part of Open Source, only
-// part of Enterprise, if any. IOW it should be the very first commit after the
-// fork point, on the Enterprise branch.
-func SetFirstEnterpriseCommit(sha string) {
-	firstEnterpriseCommit = sha
-}
-
-func CheckMenderCompliance(t *testing.T) {
-	t.Run("Checking Mender compliance", func(t *testing.T) {
-		err := checkMenderCompliance()
-		assert.NoError(t, err)
-	})
-}
+	"github.com/mendersoftware/mendertesting/config"
+)
 
-type MenderComplianceError struct {
-	Output string
-	Err    error
-}
+// testYear is the year of the commits in the test repositories.
+const testYear = 2026
 
-func (m *MenderComplianceError) Error() string {
-	return fmt.Sprintf("MenderCompliance failed with error: %s\nOutput: %s\n", m.Err, m.Output)
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
 }
 
-func checkMenderCompliance() error {
-	pathToTool, err := locatePackage()
-	if err != nil {
-		return err
-	}
-
-	args := []string{path.Join(pathToTool, "check_license_source_files.sh")}
-	if firstEnterpriseCommit != "" {
-		args = append(args, "--ent-start-commit", firstEnterpriseCommit)
+// newTestRepo creates a git repository with the given files and a single
+// commit with the given message, made in testYear, and points the commit
+// check at that commit.
+func newTestRepo(t *testing.T, message string, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0644))
 	}
-	cmd := exec.Command("bash", args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return &MenderComplianceError{
-			Err:    err,
-			Output: string(output),
-		}
+	date := fmt.Sprintf("%d-06-01T12:00:00", testYear)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"commit", "-q", "--allow-empty", "-m", message},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test Testison",
+			"GIT_AUTHOR_EMAIL=test@test.com",
+			"GIT_COMMITTER_NAME=Test Testison",
+			"GIT_COMMITTER_EMAIL=test@test.com",
+			"GIT_AUTHOR_DATE="+date,
+			"GIT_COMMITTER_DATE="+date,
+		)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
 	}
 
-	args = []string{path.Join(pathToTool, "check_commits.sh")}
-	cmd = exec.Command("bash", args...)
-	output, err = cmd.CombinedOutput()
-	if err != nil {
-		return &MenderComplianceError{
-			Err:    err,
-			Output: string(output),
-		}
-	}
-
-	args = []string{path.Join(pathToTool, "check_license.sh")}
-	args = append(args, known_license_files...)
-	cmd = exec.Command("bash", args...)
-	output, err = cmd.CombinedOutput()
-	if err != nil {
-		return &MenderComplianceError{
-			Err:    err,
-			Output: string(output),
-		}
-	}
-	return nil
+	setenv(t, "COMMIT_RANGE", "HEAD")
+	return dir
 }
 
-func locatePackage() (string, error) {
-	finalpath := path.Join("vendor", packageLocation)
-	_, err := os.Stat(finalpath)
-	if err == nil {
-		return finalpath, nil
-	}
+const signedOff = "chore: initial\n\nSigned-off-by: Test Testison <test@test.com>"
 
-	gopath := os.Getenv("GOPATH")
-	if gopath == "" {
-		return "", errors.New("Cannot check for licenses if " +
-			"mendertesting is not vendored and GOPATH is unset.")
+// compliantFiles returns the files of a minimal compliant repository, with
+// the given files added.
+func compliantFiles(extra map[string]string) map[string]string {
+	license := fmt.Sprintf("Copyright %d Northern.tech\n", testYear)
+	files := map[string]string{
+		"LICENSE":                 license,
+		"LIC_FILES_CHKSUM.sha256": checksum(license) + "  LICENSE\n",
 	}
-
-	paths := strings.Split(gopath, ":")
-	for i := 0; i < len(paths); i++ {
-		finalpath = path.Join(paths[i], "src", packageLocation)
-		_, err := os.Stat(finalpath)
-		if err == nil {
-			return finalpath, nil
-		}
+	for name, content := range extra {
+		files[name] = content
 	}
-
-	return "", fmt.Errorf("Package '%s' could not be located anywhere in GOPATH (%s)",
-		packageLocation, gopath)
+	return files
 }
 
-func resetKnownLicenses() {
-	known_license_files = []string{}
+// goSource returns a Go source file with an Open Source license header.
+func goSource(holder string) string {
+	return fmt.Sprintf("// Copyright %d %s\n//\n"+
+		"//    Licensed under the Apache License, Version 2.0 (the \"License\");\n"+
+		"//    you may not use this file except in compliance with the License.\n"+
+		"//    You may obtain a copy of the License at\n//\n"+
+		"//        http://www.apache.org/licenses/LICENSE-2.0\n//\n"+
+		"//    Unless required by applicable law or agreed to in writing, software\n"+
+		"//    distributed under the License is distributed on an \"AS IS\" BASIS,\n"+
+		"//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or "+
+		"implied.\n"+
+		"//    See the License for the specific language governing permissions and\n"+
+		"//    limitations under the License.\n\npackage main\n", testYear, holder)
 }
 
 func TestMockLicenses(t *testing.T) {
-	hierarchy := path.Join("tmp/src", packageLocation)
-
-	// Create whole src structure. This is just in case this is tested out-
-	// of-tree.
-	require.NoError(t, os.MkdirAll(hierarchy, 0755))
-	// Remove final component.
-	require.NoError(t, os.Remove(hierarchy))
-	// And replace with symlink to here.
-	here, err := os.Getwd()
-	require.NoError(t, err)
-	require.NoError(t, os.Symlink(here, hierarchy))
-	defer os.RemoveAll("tmp")
-
-	// Update GOPATH.
-	oldGopath := os.Getenv("GOPATH")
-	require.NoError(t, os.Setenv("GOPATH", path.Join(here, "tmp")))
-	defer os.Setenv("GOPATH", oldGopath)
-
-	assert.NoError(t, checkMenderCompliance())
+	assert.NoError(t, checkMenderCompliance(Options{
+		RepoRoot: newTestRepo(t, signedOff, compliantFiles(nil)),
+	}))
 
 	// Now try an unexpected license.
 	t.Run("Testing unexpected license", func(t *testing.T) {
-		t.Log("Testing unexpected license")
-		fd, err := os.Create("LICENSE.unexpected")
-		require.NoError(t, err)
-		fd.Close()
-		defer os.RemoveAll("LICENSE.unexpected")
-
-		assert.Error(t, checkMenderCompliance())
+		dir := newTestRepo(t, signedOff, compliantFiles(map[string]string{
+			"LICENSE.unexpected": "",
+		}))
+		assert.Error(t, checkMenderCompliance(Options{RepoRoot: dir}))
 	})
 
 	// Now try a Godep without license.
 	t.Run("Testing Godep without a license", func(t *testing.T) {
-		t.Log("Testing Godep without a license")
-		require.NoError(t, os.MkdirAll("vendor/dummy-site.org/test-repo", 0755))
-		fd, err := os.Create("vendor/dummy-site.org/test-repo/test.go")
-		require.NoError(t, err)
-		fd.Close()
-
-		// LIFO order, we want to remove vendor/dummy-site.org first,
-		// then potentially vendor, but not if it has other files in it.
-		defer os.Remove("vendor")
-		defer os.RemoveAll("vendor/dummy-site.org")
-
-		assert.Error(t, checkMenderCompliance())
+		dir := newTestRepo(t, signedOff, compliantFiles(map[string]string{
+			"vendor/dummy-site.org/test-repo/test.go": "",
+		}))
+		assert.Error(t, checkMenderCompliance(Options{RepoRoot: dir}))
 	})
 
 	// Now try a Godep without license, but with README.md.
 	t.Run("Testing Godep without license, but with README.md", func(t *testing.T) {
-		t.Log("Testing Godep without license, but with README.md")
-		require.NoError(t, os.MkdirAll("vendor/dummy-site.org/test-repo", 0755))
-		fd, err := os.Create("vendor/dummy-site.org/test-repo/test.go")
-		require.NoError(t, err)
-		fd.Close()
-		fd, err = os.Create("vendor/dummy-site.org/test-repo/README.md")
-		require.NoError(t, err)
-		fd.Close()
-
-		// LIFO order, we want to remove vendor/dummy-site.org first,
-		// then potentially vendor, but not if it has other files in it.
-		defer os.Remove("vendor")
-		defer os.RemoveAll("vendor/dummy-site.org")
-
-		assert.Error(t, checkMenderCompliance())
+		dir := newTestRepo(t, signedOff, compliantFiles(map[string]string{
+			"vendor/dummy-site.org/test-repo/test.go":   "",
+			"vendor/dummy-site.org/test-repo/README.md": "",
+		}))
+		assert.Error(t, checkMenderCompliance(Options{RepoRoot: dir}))
 	})
 
 	// Now try a Godep with license in README.md, but no checksum.
 	t.Run("Testing Godep with license, but no checksum", func(t *testing.T) {
-		t.Log("Testing Godep with license, but no checksum")
-		require.NoError(t, os.MkdirAll("tmp/vendor/dummy-site.org/test-repo", 0755))
-		require.NoError(t, os.Chdir("tmp"))
-		defer os.Chdir("..")
-		fd, err := os.Create("vendor/dummy-site.org/test-repo/test.go")
-		require.NoError(t, err)
-		fd.Close()
-		fd, err = os.Create("vendor/dummy-site.org/test-repo/README.md")
-		require.NoError(t, err)
-		fd.Close()
-
-		SetLicenseFileForDependency("vendor/dummy-site.org/test-repo/README.md")
-		defer resetKnownLicenses()
-
-		defer os.Remove("tmp")
-
-		assert.Error(t, checkMenderCompliance())
+		dir := newTestRepo(t, signedOff, compliantFiles(map[string]string{
+			"vendor/dummy-site.org/test-repo/test.go":   "",
+			"vendor/dummy-site.org/test-repo/README.md": "",
+		}))
+		opts := Options{
+			RepoRoot:          dir,
+			KnownLicenseFiles: []string{"vendor/dummy-site.org/test-repo/README.md"},
+		}
+		assert.Error(t, checkMenderCompliance(opts))
 	})
 
 	// Now try a Godep with license in README.md, with checksum.
 	t.Run("Testing Godep with license in README.md, with checksum", func(t *testing.T) {
-		t.Log("Testing Godep with license in README.md, with checksum")
-		// We need a custom LIC_FILES_CHKSUM.sha256, so use a temp dir
-		// for this one.
-		require.NoError(t, os.MkdirAll("tmp/vendor/dummy-site.org/test-repo", 0755))
-		require.NoError(t, os.Chdir("tmp"))
-		defer os.Chdir("..")
-		fd, err := os.Create("vendor/dummy-site.org/test-repo/test.go")
-		require.NoError(t, err)
-		fd.Close()
-		fd, err = os.Create("vendor/dummy-site.org/test-repo/README.md")
-		require.NoError(t, err)
-		fd.Close()
-		fd, err = os.Create("LICENSE")
-		fmt.Fprintln(fd, "Copyright 2026 Northern.tech")
-		require.NoError(t, err)
-		fd.Close()
-
-		fd, err = os.Create("LIC_FILES_CHKSUM.sha256")
-		fmt.Fprintln(fd, "ab71978f3b1ec37e61e2cd1ebe32c7a2958ab6a1326efaffed735073465699d6  LICENSE")
-		fmt.Fprintln(fd, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  vendor/dummy-site.org/test-repo/README.md")
-		require.NoError(t, err)
-		fd.Close()
-
-		SetLicenseFileForDependency("vendor/dummy-site.org/test-repo/README.md")
-		defer resetKnownLicenses()
-
-		defer os.Remove("tmp")
-
-		assert.NoError(t, checkMenderCompliance())
+		files := compliantFiles(map[string]string{
+			"vendor/dummy-site.org/test-repo/test.go":   "",
+			"vendor/dummy-site.org/test-repo/README.md": "",
+		})
+		files["LIC_FILES_CHKSUM.sha256"] += checksum("") +
+			"  vendor/dummy-site.org/test-repo/README.md\n"
+		opts := Options{
+			RepoRoot:          newTestRepo(t, signedOff, files),
+			KnownLicenseFiles: []string{"vendor/dummy-site.org/test-repo/README.md"},
+		}
+		assert.NoError(t, checkMenderCompliance(opts))
 	})
 
-	// Now try an invalid GOPATH.
+	// The checks don't need an installed copy of mendertesting, so GOPATH is
+	// irrelevant.
 	t.Run("Testing with an invalid GOPATH", func(t *testing.T) {
-		t.Log("Testing with an invalid GOPATH")
-		require.NoError(t, os.Setenv("GOPATH", "/invalid"))
-
-		assert.Error(t, checkMenderCompliance())
+		setenv(t, "GOPATH", "/invalid")
+		dir := newTestRepo(t, signedOff, compliantFiles(nil))
+		assert.NoError(t, checkMenderCompliance(Options{RepoRoot: dir}))
 	})
 
-	// Now try an unset GOPATH.
 	t.Run("Try to unset the GOPATH", func(t *testing.T) {
-		t.Log("Try to unset the GOPATH")
-		require.NoError(t, os.Unsetenv("GOPATH"))
-
-		assert.Error(t, checkMenderCompliance())
+		setenv(t, "GOPATH", "")
+		dir := newTestRepo(t, signedOff, compliantFiles(nil))
+		assert.NoError(t, checkMenderCompliance(Options{RepoRoot: dir}))
 	})
 }
 
 func TestLicenses(t *testing.T) {
-	assert.NoError(t, checkMenderCompliance())
+	assert.NoError(t, checkMenderCompliance(Options{}))
 }
 
 func TestLicensesWithEnterprise(t *testing.T) {
@@ -291,14 +193,15 @@ func TestLicensesWithEnterprise(t *testing.T) {
 	require.NoError(t, err)
 
 	// Should produce the same result as nothing.
-	SetFirstEnterpriseCommit(string(output))
-	defer SetFirstEnterpriseCommit("")
-	assert.NoError(t, checkMenderCompliance())
+	opts := Options{
+		FirstEnterpriseCommit: strings.TrimSpace(string(output)),
+	}
+	assert.NoError(t, checkMenderCompliance(opts))
 }
 
 func TestCommercialLicense(t *testing.T) {
 	// Test a commercial license in a temporary folder.
-	tmpdir, err := ioutil.TempDir("", "")
+	tmpdir, err := os.MkdirTemp("", "")
 	require.NoError(t, err)
 	defer os.RemoveAll(tmpdir)
 
@@ -311,36 +214,136 @@ func TestCommercialLicense(t *testing.T) {
 }
 
 func TestMisformedLicenseChecksumLines(t *testing.T) {
+	files := compliantFiles(map[string]string{
+		"vendor/dummy-site.org/test-repo/test.go":   "",
+		"vendor/dummy-site.org/test-repo/README.md": "",
+	})
+	// This is one letter short of a full shasum line
+	files["LIC_FILES_CHKSUM.sha256"] = checksum(files["LICENSE"])[1:] + "  LICENSE\n" +
+		checksum("") + "  vendor/dummy-site.org/test-repo/README.md\n"
+	opts := Options{
+		RepoRoot:          newTestRepo(t, signedOff, files),
+		KnownLicenseFiles: []string{"vendor/dummy-site.org/test-repo/README.md"},
+	}
 
-	t.Log("Testing Godep with license in README.md, with checksum")
-	// We need a custom LIC_FILES_CHKSUM.sha256, so use a temp dir
-	// for this one.
-	require.NoError(t, os.MkdirAll("tmp/vendor/dummy-site.org/test-repo", 0755))
-	require.NoError(t, os.Chdir("tmp"))
-	defer os.RemoveAll("tmp")
-	defer os.Chdir("..")
-	fd, err := os.Create("vendor/dummy-site.org/test-repo/test.go")
-	require.NoError(t, err)
-	fd.Close()
-	fd, err = os.Create("vendor/dummy-site.org/test-repo/README.md")
-	require.NoError(t, err)
-	fd.Close()
-	fd, err = os.Create("LICENSE")
-	fmt.Fprintln(fd, "Copyright 2020 Northern.tech")
-	require.NoError(t, err)
-	fd.Close()
+	err := checkMenderCompliance(opts)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		"LIC_FILES_CHKSUM.sha256:1: improperly formatted checksum line")
+}
 
-	fd, err = os.Create("LIC_FILES_CHKSUM.sha256")
-	// This is one letter short of a full shasum line
-	fmt.Fprintln(fd, "8c317e825d10807ce0a5e199300a68ea5efecce74c26e92cd3472c724b73d78  LICENSE")
-	fmt.Fprintln(fd, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  vendor/dummy-site.org/test-repo/README.md")
+func TestMenderComplianceErrorFindings(t *testing.T) {
+	dir := newTestRepo(t, "chore: initial", map[string]string{
+		"LICENSE":                 "Copyright 2000 Northern.tech AS\n",
+		"LIC_FILES_CHKSUM.sha256": "not a checksum\n",
+		"main.go":                 "package main\n",
+	})
+
+	opts := Options{RepoRoot: dir}
+	cfg, err := loadConfig(opts)
 	require.NoError(t, err)
-	fd.Close()
+	err = checkLicenses(cfg, opts)
+	var complianceErr *MenderComplianceError
+	require.True(t, errors.As(err, &complianceErr))
+	assert.Empty(t, complianceErr.FindingsOf(CheckHeaders))
+	findings := complianceErr.FindingsOf(CheckLicenses)
+	require.Len(t, findings, 2)
+	assert.Equal(t, Finding{
+		Check:   CheckLicenses,
+		Path:    "LIC_FILES_CHKSUM.sha256",
+		Line:    1,
+		Rule:    "checksum-format",
+		Message: "improperly formatted checksum line",
+	}, findings[0])
+	assert.Equal(t, "LIC_FILES_CHKSUM.sha256:1: improperly formatted checksum line",
+		findings[0].String())
+	assert.EqualError(t, errors.Unwrap(err), "2 license problems found")
+
+	err = checkSourceHeaders(cfg, opts)
+	require.True(t, errors.As(err, &complianceErr))
+	findings = complianceErr.FindingsOf(CheckHeaders)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "main.go", findings[0].Path)
+	assert.Equal(t, "license", findings[0].Rule)
+
+	// All checks run, even if the first one fails.
+	err = checkMenderCompliance(opts)
+	require.True(t, errors.As(err, &complianceErr))
+	assert.Len(t, complianceErr.FindingsOf(CheckHeaders), 1)
+	assert.Len(t, complianceErr.FindingsOf(CheckCommits), 1)
+	assert.Len(t, complianceErr.FindingsOf(CheckLicenses), 2)
+	assert.Len(t, complianceErr.FindingsOf(CheckLicenseYear), 1)
+}
+
+func TestCheckMenderComplianceSubtests(t *testing.T) {
+	dir := newTestRepo(t, signedOff, compliantFiles(map[string]string{
+		"main.go": goSource("Northern.tech AS"),
+	}))
+
+	// Runs "Checking Mender compliance/headers" and so on.
+	CheckMenderComplianceWithOptions(t, Options{RepoRoot: dir})
+}
+
+func TestConfigFile(t *testing.T) {
+	dir := newTestRepo(t, "chore: initial", compliantFiles(map[string]string{
+		config.FileName: "checks: [licenses, license-year]\n",
+		"main.go":       "package main\n",
+	}))
+	// The headers and commits checks would fail, but are not enabled.
+	assert.NoError(t, checkMenderCompliance(Options{RepoRoot: dir}))
+
+	dir = newTestRepo(t, "chore: initial", compliantFiles(map[string]string{
+		config.FileName: "checks: [headers]\ncopyright_holder: Acme Inc.\n",
+		"main.go":       goSource("Acme Inc."),
+		"other.go":      goSource("Northern.tech AS"),
+	}))
+	err := checkMenderCompliance(Options{RepoRoot: dir})
+	var complianceErr *MenderComplianceError
+	require.True(t, errors.As(err, &complianceErr))
+	findings := complianceErr.FindingsOf(CheckHeaders)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "other.go", findings[0].Path)
+
+	dir = newTestRepo(t, "chore: initial", compliantFiles(map[string]string{
+		config.FileName: "checks: [header]\n",
+	}))
+	assert.EqualError(t, checkMenderCompliance(Options{RepoRoot: dir}),
+		config.FileName+`: unknown check "header"`)
+}
 
+func TestDefaultOptions(t *testing.T) {
 	SetLicenseFileForDependency("vendor/dummy-site.org/test-repo/README.md")
-	defer resetKnownLicenses()
+	SetFirstEnterpriseCommit("0123456789abcdef")
+	defer func() {
+		defaultOptionsLock.Lock()
+		defaultOptions = Options{}
+		defaultOptionsLock.Unlock()
+	}()
+
+	opts := DefaultOptions()
+	assert.Equal(t, []string{"vendor/dummy-site.org/test-repo/README.md"},
+		opts.KnownLicenseFiles)
+	assert.Equal(t, "0123456789abcdef", opts.FirstEnterpriseCommit)
+
+	// Modifying the returned copy must not leak into the defaults.
+	opts.KnownLicenseFiles[0] = "LICENSE.other"
+	opts.KnownLicenseFiles = append(opts.KnownLicenseFiles, "COPYING")
+	assert.Equal(t, []string{"vendor/dummy-site.org/test-repo/README.md"},
+		DefaultOptions().KnownLicenseFiles)
+}
 
-	err = checkMenderCompliance()
-	assert.Error(t, err, err.Error())
-	assert.Contains(t, err.Error(), "shasum: WARNING: 1 line is improperly formatted")
+func setenv(t *testing.T, name, value string) {
+	old, wasSet := os.LookupEnv(name)
+	if value == "" {
+		require.NoError(t, os.Unsetenv(name))
+	} else {
+		require.NoError(t, os.Setenv(name, value))
+	}
+	t.Cleanup(func() {
+		if wasSet {
+			os.Setenv(name, old)
+		} else {
+			os.Unsetenv(name)
+		}
+	})
 }
@@ -0,0 +1,99 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package licenses
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ChecksumFileName is the name of the file listing the reviewed license
+// files, in the format of sha256sum(1).
+const ChecksumFileName = "LIC_FILES_CHKSUM.sha256"
+
+// ChecksumEntry is a single "<sha256>  <path>" line of a checksum file.
+type ChecksumEntry struct {
+	Sum  string
+	Path string
+	// Line is the 1-based line number in the checksum file.
+	Line int
+}
+
+// ChecksumFile is a parsed checksum file.
+type ChecksumFile struct {
+	Entries []ChecksumEntry
+	// Malformed holds the 1-based line numbers of lines which are neither
+	// empty, a comment nor a checksum entry.
+	Malformed []int
+}
+
+var checksumLineRegexp = regexp.MustCompile(`^([0-9a-fA-F]{64}) [ *](.+)$`)
+
+// ParseChecksumFile parses a checksum file. Empty lines and lines starting
+// with "#" are ignored.
+func ParseChecksumFile(r io.Reader) (*ChecksumFile, error) {
+	result := &ChecksumFile{}
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entry, ok := parseChecksumLine(line, lineNo)
+		if !ok {
+			result.Malformed = append(result.Malformed, lineNo)
+			continue
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+	return result, scanner.Err()
+}
+
+func parseChecksumLine(line string, lineNo int) (ChecksumEntry, bool) {
+	m := checksumLineRegexp.FindStringSubmatch(line)
+	if m == nil {
+		return ChecksumEntry{}, false
+	}
+	return ChecksumEntry{Sum: strings.ToLower(m[1]), Path: m[2], Line: lineNo}, true
+}
+
+// Lookup returns the entry for the given path, if any.
+func (c *ChecksumFile) Lookup(name string) (ChecksumEntry, bool) {
+	for _, entry := range c.Entries {
+		if entry.Path == name {
+			return entry, true
+		}
+	}
+	return ChecksumEntry{}, false
+}
+
+// FileSum returns the hex encoded SHA-256 sum of a file.
+func FileSum(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
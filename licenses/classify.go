@@ -0,0 +1,141 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package licenses
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// The corpus holds the texts of common licenses, named after their SPDX
+// license identifiers. A license text alone doesn't tell whether a later
+// version of the GNU licenses may be used too, so they are named with the
+// "-only" identifiers, like GPL-3.0-only.
+//
+//go:embed corpus/*.txt
+var corpusFiles embed.FS
+
+// MinCoverage is the share of the text of a license which a file must
+// contain to be identified as that license.
+const MinCoverage = 0.8
+
+// shingleSize is the number of consecutive words compared between texts.
+const shingleSize = 3
+
+// corpusLicense is a license of the corpus.
+type corpusLicense struct {
+	id       string
+	shingles map[string]bool
+}
+
+var corpus = loadCorpus()
+
+func loadCorpus() []corpusLicense {
+	entries, err := corpusFiles.ReadDir("corpus")
+	if err != nil {
+		panic(err)
+	}
+	licenses := make([]corpusLicense, 0, len(entries))
+	for _, entry := range entries {
+		text, err := corpusFiles.ReadFile(path.Join("corpus", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		licenses = append(licenses, corpusLicense{
+			id:       strings.TrimSuffix(entry.Name(), ".txt"),
+			shingles: shingles(string(text)),
+		})
+	}
+	return licenses
+}
+
+// CorpusLicenses returns the SPDX license identifiers of all licenses
+// Identify knows, sorted.
+func CorpusLicenses() []string {
+	ids := make([]string, len(corpus))
+	for i, license := range corpus {
+		ids[i] = license.id
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// words returns the normalized words of a text: in lower case, without
+// punctuation, and with the spelling variants of the corpus unified.
+func words(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range fields {
+		switch word {
+		case "licence":
+			fields[i] = "license"
+		case "https":
+			fields[i] = "http"
+		}
+	}
+	return fields
+}
+
+func shingles(text string) map[string]bool {
+	w := words(text)
+	result := make(map[string]bool)
+	for i := 0; i+shingleSize <= len(w); i++ {
+		result[strings.Join(w[i:i+shingleSize], " ")] = true
+	}
+	return result
+}
+
+// Identify returns the SPDX license identifiers of the licenses in a text,
+// sorted, or none if it isn't a known license. A text is identified as a
+// license of the corpus if it contains at least MinCoverage of it, apart
+// from differences in case, punctuation and white space. Copyright lines
+// and names of the copyright holders are allowed to differ.
+//
+// A text may contain several licenses, like a dependency under both MIT
+// and Apache-2.0. The licenses which cover most of the text are taken
+// first, so that a text isn't also identified as the licenses it contains
+// most of, like BSD-2-Clause for BSD-3-Clause, or GPL-3.0-only for AGPL-3.0-only.
+func Identify(text string) []string {
+	remaining := shingles(text)
+	var ids []string
+	for {
+		best, bestMatched := -1, 0
+		for i, license := range corpus {
+			matched := 0
+			for s := range license.shingles {
+				if remaining[s] {
+					matched++
+				}
+			}
+			if float64(matched) >= MinCoverage*float64(len(license.shingles)) &&
+				matched > bestMatched {
+				best, bestMatched = i, matched
+			}
+		}
+		if best < 0 {
+			break
+		}
+		ids = append(ids, corpus[best].id)
+		for s := range corpus[best].shingles {
+			delete(remaining, s)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
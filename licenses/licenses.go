@@ -0,0 +1,374 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package licenses verifies the licenses of a Mender repository and its
+// dependencies: the year in the top-level LICENSE, the reviewed checksums in
+// LIC_FILES_CHKSUM.sha256, and that every vendored Go file is covered by a
+// license.
+//
+// It implements the same rules as check_license.sh, without depending on
+// shasum(1), and keeps the checksum file up to date. Besides, it identifies
+// the licenses of the dependencies, to check them against a Policy.
+package licenses
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mendersoftware/mendertesting/internal/git"
+)
+
+// Rule identifies which license rule a finding violates.
+type Rule string
+
+const (
+	// RuleTopLevelLicense means that there is no license file at the top
+	// level of the repository.
+	RuleTopLevelLicense Rule = "top-level-license"
+	// RuleCopyrightYear means that the top-level LICENSE doesn't have the
+	// year of the latest commit.
+	RuleCopyrightYear Rule = "copyright-year"
+	// RuleChecksumFormat means that a line in the checksum file is
+	// improperly formatted.
+	RuleChecksumFormat Rule = "checksum-format"
+	// RuleChecksumMismatch means that a listed file is missing, or that its
+	// contents don't match the reviewed checksum.
+	RuleChecksumMismatch Rule = "checksum-mismatch"
+	// RuleUnlisted means that a license file has no entry in the checksum
+	// file, and is not in .COVERED_LICENSES either.
+	RuleUnlisted Rule = "unlisted-license"
+	// RuleUncovered means that a vendored Go package has no license file in
+	// its directory or any parent directory.
+	RuleUncovered Rule = "uncovered-dependency"
+	// RuleDeniedLicense means that the license of a dependency is not
+	// allowed by the Policy.
+	RuleDeniedLicense Rule = "denied-license"
+	// RuleLicenseGroup means that a license file is listed in a group of
+	// the checksum file named after another license than its own.
+	RuleLicenseGroup Rule = "license-group"
+)
+
+// Finding is a single license problem.
+type Finding struct {
+	Rule Rule
+	// Path is slash separated and relative to the checked root.
+	Path string
+	// Line is the 1-based line in Path the finding is about, if any.
+	Line    int
+	Message string
+}
+
+func (f Finding) String() string {
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", f.Path, f.Line, f.Message)
+	}
+	return fmt.Sprintf("%s: %s", f.Path, f.Message)
+}
+
+// CoveredLicensesFileName lists license files which are excluded from the
+// checksum check, one path per line. This is for licenses which are not
+// used, or superseded by a commercial license.
+const CoveredLicensesFileName = ".COVERED_LICENSES"
+
+// Checker checks the licenses of a repository.
+type Checker struct {
+	// Root is the root of the git repository to check. Defaults to the
+	// current working directory.
+	Root string
+
+	// KnownLicenseFiles are license files for dependencies which don't
+	// follow the common license file names. They must be listed in the
+	// checksum file.
+	KnownLicenseFiles []string
+
+	// CopyrightHolder is the holder in the copyright of the top-level
+	// license. Defaults to DefaultCopyrightHolder.
+	CopyrightHolder string
+
+	// Policy restricts the licenses of the dependencies.
+	Policy Policy
+}
+
+// DefaultCopyrightHolder is the copyright holder of Mender repositories.
+const DefaultCopyrightHolder = "Northern.tech"
+
+// Check runs all license checks and returns the findings. The error is only
+// non-nil if the check itself could not be carried out.
+func (c *Checker) Check(ctx context.Context) ([]Finding, error) {
+	findings, err := c.CheckTopLevel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	more, err := c.CheckDependencies()
+	if err != nil {
+		return nil, err
+	}
+	return append(findings, more...), nil
+}
+
+// CheckDependencies runs the checks of the dependency licenses: the
+// checksum file and its license groups, the vendor coverage, and the
+// Policy.
+func (c *Checker) CheckDependencies() ([]Finding, error) {
+	findings, err := c.CheckChecksums()
+	if err != nil {
+		return nil, err
+	}
+	for _, check := range []func() ([]Finding, error){
+		c.CheckGroups,
+		func() ([]Finding, error) { return CheckVendorCoverage(c.Root, c.KnownLicenseFiles) },
+		c.CheckPolicy,
+	} {
+		more, err := check()
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, more...)
+	}
+	return findings, nil
+}
+
+// CheckTopLevel checks that there is a license file at the top level, and
+// that LICENSE or LICENSE.md has a Northern.tech copyright for the year of
+// the latest authored commit.
+func CheckTopLevel(ctx context.Context, root string) ([]Finding, error) {
+	return (&Checker{Root: root}).CheckTopLevel(ctx)
+}
+
+// CheckTopLevel checks that there is a license file at the top level, and
+// that LICENSE or LICENSE.md has a copyright of the holder for the year of
+// the latest authored commit.
+func (c *Checker) CheckTopLevel(ctx context.Context) ([]Finding, error) {
+	root := c.Root
+	holder := c.CopyrightHolder
+	if holder == "" {
+		holder = DefaultCopyrightHolder
+	}
+	var findings []Finding
+	topLevel, err := filepath.Glob(filepath.Join(root, "LICENSE*"))
+	if err != nil {
+		return nil, err
+	}
+	copying, err := filepath.Glob(filepath.Join(root, "COPYING*"))
+	if err != nil {
+		return nil, err
+	}
+	if len(topLevel)+len(copying) == 0 {
+		findings = append(findings, Finding{
+			Rule:    RuleTopLevelLicense,
+			Path:    "LICENSE",
+			Message: "No top level license file.",
+		})
+	}
+
+	year, err := latestCommitYear(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	re := regexp.MustCompile(`(?i)Copyright *` + strconv.Itoa(year) + ` *` +
+		regexp.QuoteMeta(holder))
+	for _, name := range []string{"LICENSE", "LICENSE.md"} {
+		content, err := os.ReadFile(filepath.Join(root, name))
+		if err == nil && re.Match(content) {
+			return findings, nil
+		}
+	}
+	return append(findings, Finding{
+		Rule: RuleCopyrightYear,
+		Path: "LICENSE",
+		Message: fmt.Sprintf("'Copyright %d %s' not found in LICENSE. "+
+			"Wrong year maybe?", year, holder),
+	}), nil
+}
+
+// latestCommitYear returns the year of the commit with the latest author
+// date, which is not necessarily HEAD.
+func latestCommitYear(ctx context.Context, root string) (int, error) {
+	lines, err := git.Lines(ctx, root, "log", "--no-merges",
+		"--format=%at %ad", "--date=format:%Y")
+	if err != nil {
+		return 0, err
+	}
+	var latest int64 = -1
+	year := 0
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		timestamp, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil || timestamp <= latest {
+			continue
+		}
+		if y, err := strconv.Atoi(fields[1]); err == nil {
+			latest, year = timestamp, y
+		}
+	}
+	if latest < 0 {
+		return 0, fmt.Errorf("no commits found in %q", root)
+	}
+	return year, nil
+}
+
+// CheckChecksums verifies the checksum file, and checks that every license
+// file in the repository is listed in it with the correct checksum.
+func (c *Checker) CheckChecksums() ([]Finding, error) {
+	f, err := os.Open(filepath.Join(c.Root, ChecksumFileName))
+	if err != nil {
+		return nil, err
+	}
+	sums, err := ParseChecksumFile(f)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, known := range c.KnownLicenseFiles {
+		if _, ok := sums.Lookup(known); !ok {
+			findings = append(findings, Finding{
+				Rule:    RuleUnlisted,
+				Path:    known,
+				Message: fmt.Sprintf("does not have a checksum in %s", ChecksumFileName),
+			})
+		}
+	}
+	for _, line := range sums.Malformed {
+		findings = append(findings, Finding{
+			Rule:    RuleChecksumFormat,
+			Path:    ChecksumFileName,
+			Line:    line,
+			Message: "improperly formatted checksum line",
+		})
+	}
+	for _, entry := range sums.Entries {
+		sum, err := FileSum(filepath.Join(c.Root, filepath.FromSlash(entry.Path)))
+		if os.IsNotExist(err) {
+			findings = append(findings, Finding{
+				Rule: RuleChecksumMismatch,
+				Path: entry.Path,
+				Message: fmt.Sprintf("is listed on line %d of %s, but does not exist",
+					entry.Line, ChecksumFileName),
+			})
+		} else if err != nil {
+			return nil, err
+		} else if sum != entry.Sum {
+			findings = append(findings, Finding{
+				Rule: RuleChecksumMismatch,
+				Path: entry.Path,
+				Message: fmt.Sprintf("does not match the checksum on line %d of %s",
+					entry.Line, ChecksumFileName),
+			})
+		}
+	}
+
+	covered, err := readCoveredLicenses(c.Root)
+	if err != nil {
+		return nil, err
+	}
+	files, err := FindLicenseFiles(c.Root)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range files {
+		if covered[name] {
+			continue
+		}
+		// Listed files with a wrong checksum have been reported above.
+		if _, ok := sums.Lookup(name); !ok {
+			findings = append(findings, Finding{
+				Rule:    RuleUnlisted,
+				Path:    name,
+				Message: fmt.Sprintf("has no entry in %s", ChecksumFileName),
+			})
+		}
+	}
+	return findings, nil
+}
+
+func readCoveredLicenses(root string) (map[string]bool, error) {
+	covered := map[string]bool{}
+	f, err := os.Open(filepath.Join(root, CoveredLicensesFileName))
+	if os.IsNotExist(err) {
+		return covered, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		covered[scanner.Text()] = true
+	}
+	return covered, scanner.Err()
+}
+
+// IsLicenseFileName reports whether a file name is one of the common license
+// file names: LICENSE, LICENCE, LICENSE.*, LICENCE.* or COPYING, in any
+// case. Source files named like this are not license files.
+func IsLicenseFileName(name string) bool {
+	lower := strings.ToLower(name)
+	switch path.Ext(lower) {
+	case ".go", ".c", ".cpp":
+		return false
+	}
+	if lower == "copying" {
+		return true
+	}
+	for _, base := range []string{"license", "licence"} {
+		if lower == base || strings.HasPrefix(lower, base+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// FindLicenseFiles returns the slash separated paths of all files in the
+// repository with a common license file name, sorted.
+func FindLicenseFiles(root string) ([]string, error) {
+	if root == "" {
+		root = "."
+	}
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !IsLicenseFileName(d.Name()) {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
@@ -0,0 +1,429 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package licenses
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sum(content string) string {
+	s := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(s[:])
+}
+
+// newTestRepo creates a git repository with the given files, committed at
+// the given date.
+func newTestRepo(t *testing.T, date string, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		writeFile(t, dir, name, content)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"commit", "-q", "-m", "Initial commit"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test Testison",
+			"GIT_AUTHOR_EMAIL=test@test.com",
+			"GIT_COMMITTER_NAME=Test Testison",
+			"GIT_COMMITTER_EMAIL=test@test.com",
+			"GIT_AUTHOR_DATE="+date,
+			"GIT_COMMITTER_DATE="+date,
+		)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+	require.NoError(t, os.WriteFile(p, []byte(content), 0644))
+}
+
+func rules(findings []Finding) map[string]Rule {
+	result := map[string]Rule{}
+	for _, f := range findings {
+		result[f.Path] = f.Rule
+	}
+	return result
+}
+
+func TestParseChecksumFile(t *testing.T) {
+	valid := strings.Repeat("ab", 32)
+	content := strings.Join([]string{
+		"# MIT license",
+		valid + "  LICENSE",
+		"",
+		strings.ToUpper(valid) + " *vendor/x/COPYING",
+		valid[1:] + "  LICENSE.short",
+		valid + " LICENSE.one-space",
+	}, "\n")
+	sums, err := ParseChecksumFile(strings.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, []ChecksumEntry{
+		{Sum: valid, Path: "LICENSE", Line: 2},
+		{Sum: valid, Path: "vendor/x/COPYING", Line: 4},
+	}, sums.Entries)
+	assert.Equal(t, []int{5, 6}, sums.Malformed)
+
+	entry, ok := sums.Lookup("vendor/x/COPYING")
+	assert.True(t, ok)
+	assert.Equal(t, 4, entry.Line)
+	_, ok = sums.Lookup("vendor/x")
+	assert.False(t, ok)
+}
+
+func TestIsLicenseFileName(t *testing.T) {
+	for _, name := range []string{
+		"LICENSE", "license", "LICENCE", "LICENSE.md", "License.txt", "COPYING",
+	} {
+		assert.True(t, IsLicenseFileName(name), name)
+	}
+	for _, name := range []string{
+		"LICENSES", "license.go", "LICENSE.c", "COPYING.md", "README.md",
+	} {
+		assert.False(t, IsLicenseFileName(name), name)
+	}
+}
+
+func TestChecker(t *testing.T) {
+	const (
+		topLevel = "Copyright 2020 Northern.tech AS\n"
+		mit      = "MIT License\n"
+		readme   = "Licensed under the MIT License\n"
+	)
+	files := map[string]string{
+		"LICENSE":                      topLevel,
+		"vendor/a.org/b/LICENSE":       mit,
+		"vendor/a.org/b/c/d.go":        "package d\n",
+		"vendor/e.org/f/README.md":     readme,
+		"vendor/e.org/f/g.go":          "package f\n",
+		"vendor/h.org/i/j.go":          "package i\n",
+		"vendor/h.org/i/k/l.go":        "package k\n",
+		"test/LICENSE":                 "GPL\n",
+		"LICENSE.unexpected":           "",
+		CoveredLicensesFileName:        "test/LICENSE\n",
+		"vendor/m.org/n/LICENSE.wrong": "changed\n",
+	}
+	files[ChecksumFileName] = fmt.Sprintf("%s  LICENSE\n#\n# MIT license\n"+
+		"%s  vendor/a.org/b/LICENSE\n%s  vendor/e.org/f/README.md\n"+
+		"%s  vendor/m.org/n/LICENSE.wrong\n%s  vendor/gone/LICENSE\n",
+		sum(topLevel), sum(mit), sum(readme), sum("original\n"), sum(mit))
+	root := newTestRepo(t, "2020-06-01T12:00:00", files)
+
+	checker := &Checker{
+		Root:              root,
+		KnownLicenseFiles: []string{"vendor/e.org/f/README.md"},
+	}
+	findings, err := checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{
+		"LICENSE.unexpected":           RuleUnlisted,
+		"vendor/m.org/n/LICENSE.wrong": RuleChecksumMismatch,
+		"vendor/gone/LICENSE":          RuleChecksumMismatch,
+		"vendor/h.org/i":               RuleUncovered,
+		"vendor/h.org/i/k":             RuleUncovered,
+	}, rules(findings))
+
+	// Without the known license file, README.md doesn't count.
+	checker.KnownLicenseFiles = nil
+	findings, err = checker.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, RuleUncovered, rules(findings)["vendor/e.org/f"])
+
+	// A known license file must be listed in the checksum file.
+	checker.KnownLicenseFiles = []string{"vendor/h.org/i/README.md"}
+	findings, err = checker.CheckChecksums()
+	require.NoError(t, err)
+	assert.Equal(t, RuleUnlisted, rules(findings)["vendor/h.org/i/README.md"])
+}
+
+func TestCheckTopLevel(t *testing.T) {
+	root := newTestRepo(t, "2021-06-01T12:00:00", map[string]string{
+		"LICENSE.md": "copyright  2021 northern.tech AS\n",
+	})
+	findings, err := CheckTopLevel(context.Background(), root)
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	root = newTestRepo(t, "2021-06-01T12:00:00", map[string]string{
+		"LICENSE": "Copyright 2020 Northern.tech AS\n",
+	})
+	findings, err = CheckTopLevel(context.Background(), root)
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, RuleCopyrightYear, findings[0].Rule)
+	assert.Contains(t, findings[0].Message, "2021")
+
+	root = newTestRepo(t, "2021-06-01T12:00:00", map[string]string{
+		"README.md": "No license\n",
+	})
+	findings, err = CheckTopLevel(context.Background(), root)
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	assert.Equal(t, RuleTopLevelLicense, findings[0].Rule)
+	assert.Equal(t, RuleCopyrightYear, findings[1].Rule)
+}
+
+func TestVendorPackages(t *testing.T) {
+	root := newTestRepo(t, "2020-06-01T12:00:00", map[string]string{
+		"vendor/LICENSE":           "Not a dependency\n",
+		"vendor/modules.txt":       "# a.org/b\n",
+		"vendor/a.org/b/COPYING":   "GPL\n",
+		"vendor/a.org/b/b.go":      "package b\n",
+		"vendor/a.org/b/c/c.go":    "package c\n",
+		"vendor/a.org/b/c/LICENSE": "MIT\n",
+		"vendor/a.org/b/d/e/e.go":  "package e\n",
+		"vendor/a.org/b/f/doc.md":  "No Go files\n",
+		"vendor/g.org/h/NOTICE":    "Known\n",
+		"vendor/g.org/h/i/i.go":    "package i\n",
+		"vendor/j.org/k/k.go":      "package k\n",
+		"vendor/j.org/l/l.go":      "package l\n",
+	})
+	packages, err := VendorPackages(root, []string{"./vendor/g.org/h/NOTICE"})
+	require.NoError(t, err)
+	assert.Equal(t, []Package{
+		{Dir: "vendor/a.org/b", License: "vendor/a.org/b/COPYING"},
+		{Dir: "vendor/a.org/b/c", License: "vendor/a.org/b/c/LICENSE"},
+		{Dir: "vendor/a.org/b/d/e", License: "vendor/a.org/b/COPYING"},
+		{Dir: "vendor/g.org/h/i", License: "vendor/g.org/h/NOTICE"},
+		{Dir: "vendor/j.org/k"},
+		{Dir: "vendor/j.org/l"},
+	}, packages)
+
+	// Every uncovered package is reported.
+	findings, err := CheckVendorCoverage(root, []string{"vendor/g.org/h/NOTICE"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{
+		"vendor/j.org/k": RuleUncovered,
+		"vendor/j.org/l": RuleUncovered,
+	}, rules(findings))
+
+	packages, err = VendorPackages(t.TempDir(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, packages)
+}
+
+func TestUpdateChecksums(t *testing.T) {
+	const (
+		topLevel = "Copyright 2020 Northern.tech AS\n"
+		mitA     = "MIT License\n\nCopyright (c) 2016 A\n\nPermission is hereby granted\n"
+		mitB     = "MIT License\n\nCopyright (c) 2019 B\n\nPermission is  hereby granted\n"
+		bsd      = "Redistribution and use in source and binary forms\n"
+		isc      = "Permission to use, copy, modify, and/or distribute\n"
+	)
+	root := newTestRepo(t, "2020-06-01T12:00:00", map[string]string{
+		"LICENSE":                topLevel,
+		"vendor/a.org/a/LICENSE": mitA,
+		"vendor/c.org/c/LICENSE": mitA,
+		"vendor/d.org/d/LICENSE": bsd,
+		"vendor/x.org/x/LICENSE": bsd,
+		ChecksumFileName: fmt.Sprintf("%s  LICENSE\n#\n# BSD-3-Clause\n"+
+			"%s  vendor/d.org/d/LICENSE\n%s  vendor/gone/LICENSE\n#\n# MIT license\n"+
+			"%s  vendor/a.org/a/LICENSE\n%s  vendor/c.org/c/LICENSE\n",
+			sum(topLevel), sum(bsd), sum(bsd), sum(mitA), sum(mitA)),
+	})
+	writeFile(t, root, "vendor/b.org/b/LICENSE.md", mitB)
+	writeFile(t, root, "vendor/e.org/e/COPYING", isc)
+	writeFile(t, root, "vendor/c.org/c/LICENSE", mitA+"\nChanged\n")
+
+	checker := &Checker{Root: root}
+	update, err := checker.UpdateChecksums(context.Background(), false, true)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%s  LICENSE\n#\n# BSD-3-Clause\n"+
+		"%s  vendor/d.org/d/LICENSE\n%s  vendor/x.org/x/LICENSE\n#\n# MIT license\n"+
+		"%s  vendor/a.org/a/LICENSE\n%s  vendor/b.org/b/LICENSE.md\n"+
+		"%s  vendor/c.org/c/LICENSE\n#\n# Unknown license\n%s  vendor/e.org/e/COPYING\n",
+		sum(topLevel), sum(bsd), sum(bsd), sum(mitA), sum(mitB), sum(mitA), sum(isc)),
+		update.New)
+	assert.Equal(t, []ChecksumEntry{
+		{Sum: sum(bsd), Path: "vendor/x.org/x/LICENSE", Line: 5},
+		{Sum: sum(mitB), Path: "vendor/b.org/b/LICENSE.md", Line: 9},
+		{Sum: sum(isc), Path: "vendor/e.org/e/COPYING", Line: 13},
+	}, update.Added)
+	assert.Equal(t, []string{"vendor/e.org/e/COPYING"}, update.Unknown)
+	assert.Equal(t, []ChecksumEntry{{Sum: sum(bsd), Path: "vendor/gone/LICENSE", Line: 5}},
+		update.Removed)
+	require.Len(t, update.Changed, 1)
+	assert.Equal(t, "vendor/c.org/c/LICENSE", update.Changed[0].Entry.Path)
+	assert.Equal(t, sum(mitA+"\nChanged\n"), update.Changed[0].Sum)
+	assert.Equal(t, "--- a/vendor/c.org/c/LICENSE\n+++ b/vendor/c.org/c/LICENSE\n"+
+		"@@ -3,3 +3,5 @@\n Copyright (c) 2016 A\n \n Permission is hereby granted\n"+
+		"+\n+Changed\n", update.Changed[0].Diff)
+	assert.Contains(t, update.Diff(), "-"+sum(bsd)+"  vendor/gone/LICENSE\n")
+
+	// A dry run doesn't write the file.
+	content, err := os.ReadFile(filepath.Join(root, ChecksumFileName))
+	require.NoError(t, err)
+	assert.Equal(t, update.Old, string(content))
+
+	update, err = checker.UpdateChecksums(context.Background(), true, false)
+	require.NoError(t, err)
+	content, err = os.ReadFile(filepath.Join(root, ChecksumFileName))
+	require.NoError(t, err)
+	assert.Equal(t, update.New, string(content))
+	assert.Contains(t, update.New, sum(mitA+"\nChanged\n")+"  vendor/c.org/c/LICENSE\n")
+
+	findings, err := checker.CheckChecksums()
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	// Nothing left to update.
+	update, err = checker.UpdateChecksums(context.Background(), false, false)
+	require.NoError(t, err)
+	assert.Equal(t, update.Old, update.New)
+	assert.Empty(t, update.Added)
+	assert.Empty(t, update.Changed)
+}
+
+func corpusText(t *testing.T, id string) string {
+	text, err := corpusFiles.ReadFile("corpus/" + id + ".txt")
+	require.NoError(t, err)
+	return string(text)
+}
+
+func TestIdentify(t *testing.T) {
+	for _, id := range CorpusLicenses() {
+		assert.Equal(t, []string{id}, Identify(corpusText(t, id)), id)
+	}
+
+	mit := "MIT License\n\nCopyright (c) 2012-2020 Mat Ryer and Tyler Bunnell\n\n" +
+		strings.ReplaceAll(corpusText(t, "MIT"), "\n", "\n  ")
+	assert.Equal(t, []string{"MIT"}, Identify(mit))
+
+	bsd := strings.Replace(corpusText(t, "BSD-3-Clause"), "the copyright holder",
+		"Google Inc.", 1)
+	assert.Equal(t, []string{"BSD-3-Clause"}, Identify("Copyright (c) 2009 Google\n\n"+bsd))
+
+	assert.Equal(t, []string{"Apache-2.0", "MIT"},
+		Identify(corpusText(t, "Apache-2.0")+"\n\n---\n\n"+mit))
+	assert.Empty(t, Identify("All rights reserved.\n"))
+	assert.Empty(t, Identify(""))
+}
+
+func TestCheckPolicy(t *testing.T) {
+	root := newTestRepo(t, "2020-06-01T12:00:00", map[string]string{
+		"LICENSE":                       corpusText(t, "Apache-2.0"),
+		"vendor/a.org/mit/LICENSE":      corpusText(t, "MIT"),
+		"vendor/a.org/gpl/COPYING":      corpusText(t, "GPL-3.0-only"),
+		"vendor/a.org/agpl/LICENSE.txt": corpusText(t, "AGPL-3.0-only"),
+		"vendor/a.org/own/LICENSE":      "All rights reserved.\n",
+		"vendor/a.org/dual/LICENSE":     corpusText(t, "MIT") + corpusText(t, "LGPL-2.1-only"),
+		CoveredLicensesFileName:         "vendor/a.org/own/LICENSE\n",
+	})
+	checker := &Checker{Root: root}
+	licenses, err := checker.DependencyLicenses()
+	require.NoError(t, err)
+	assert.Equal(t, []License{
+		{Path: "vendor/a.org/agpl/LICENSE.txt", IDs: []string{"AGPL-3.0-only"}},
+		{Path: "vendor/a.org/dual/LICENSE", IDs: []string{"LGPL-2.1-only", "MIT"}},
+		{Path: "vendor/a.org/gpl/COPYING", IDs: []string{"GPL-3.0-only"}},
+		{Path: "vendor/a.org/mit/LICENSE", IDs: []string{"MIT"}},
+	}, licenses)
+
+	findings, err := checker.CheckPolicy()
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	checker.Policy = Policy{Deny: []string{"AGPL-*"}}
+	findings, err = checker.CheckPolicy()
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "vendor/a.org/agpl/LICENSE.txt: is licensed under AGPL-3.0-only, "+
+		"which is denied", findings[0].String())
+
+	checker.Policy = Policy{Allow: []string{"MIT", "*GPL-3.0"}, Deny: []string{"AGPL-*"}}
+	findings, err = checker.CheckPolicy()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{
+		"vendor/a.org/agpl/LICENSE.txt": RuleDeniedLicense,
+		"vendor/a.org/dual/LICENSE":     RuleDeniedLicense,
+	}, rules(findings))
+
+	// The deprecated identifiers match the current ones.
+	checker.Policy = Policy{Allow: []string{"MIT", "GPL-3.0-only", "LGPL-2.1"}}
+	findings, err = checker.CheckPolicy()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{"vendor/a.org/agpl/LICENSE.txt": RuleDeniedLicense},
+		rules(findings))
+	checker.Policy = Policy{Deny: []string{"GPL-3.0-or-later"}}
+	findings, err = checker.CheckPolicy()
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	// Unidentified licenses are not allowed by an allow list.
+	checker.Policy = Policy{Allow: []string{"*"}}
+	writeFile(t, root, CoveredLicensesFileName, "")
+	findings, err = checker.CheckPolicy()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Rule{"vendor/a.org/own/LICENSE": RuleDeniedLicense}, rules(findings))
+}
+
+func TestCheckGroups(t *testing.T) {
+	mit := corpusText(t, "MIT")
+	bsd := corpusText(t, "BSD-3-Clause")
+	gpl := corpusText(t, "GPL-3.0-only")
+	own := "All rights reserved.\n"
+	root := newTestRepo(t, "2020-06-01T12:00:00", map[string]string{
+		"vendor/a.org/a/LICENSE": mit,
+		"vendor/b.org/b/LICENSE": bsd,
+		"vendor/c.org/c/LICENSE": own,
+		"vendor/d.org/d/LICENSE": mit,
+		"vendor/g.org/g/COPYING": gpl,
+		ChecksumFileName: fmt.Sprintf("#\n# MIT license\n%s  vendor/a.org/a/LICENSE\n"+
+			"%s  vendor/b.org/b/LICENSE\n%s  vendor/c.org/c/LICENSE\n"+
+			"#\n# Dependencies\n%s  vendor/d.org/d/LICENSE\n"+
+			"#\n# GPL-3.0\n%s  vendor/g.org/g/COPYING\n",
+			sum(mit), sum(bsd), sum(own), sum(mit), sum(gpl)),
+	})
+	checker := &Checker{Root: root}
+	findings, err := checker.CheckGroups()
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, RuleLicenseGroup, findings[0].Rule)
+	assert.Equal(t, "vendor/b.org/b/LICENSE: is identified as BSD-3-Clause, but listed under "+
+		"\"MIT license\" on line 4 of LIC_FILES_CHKSUM.sha256", findings[0].String())
+
+	// New files go to the group of their license.
+	writeFile(t, root, "vendor/e.org/e/LICENSE", "Copyright 2020 E\n\n"+bsd)
+	writeFile(t, root, "vendor/f.org/f/LICENSE", "Copyright 2020 F\n\n"+mit)
+	writeFile(t, root, "vendor/h.org/h/COPYING", "Copyright 2020 H\n\n"+gpl)
+	update, err := checker.UpdateChecksums(context.Background(), false, true)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("#\n# MIT license\n%s  vendor/a.org/a/LICENSE\n"+
+		"%s  vendor/b.org/b/LICENSE\n%s  vendor/c.org/c/LICENSE\n%s  vendor/f.org/f/LICENSE\n"+
+		"#\n# Dependencies\n%s  vendor/d.org/d/LICENSE\n"+
+		"#\n# GPL-3.0\n%s  vendor/g.org/g/COPYING\n%s  vendor/h.org/h/COPYING\n"+
+		"#\n# BSD-3-Clause\n%s  vendor/e.org/e/LICENSE\n",
+		sum(mit), sum(bsd), sum(own), sum("Copyright 2020 F\n\n"+mit), sum(mit),
+		sum(gpl), sum("Copyright 2020 H\n\n"+gpl), sum("Copyright 2020 E\n\n"+bsd)), update.New)
+	assert.Empty(t, update.Unknown)
+}
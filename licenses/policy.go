@@ -0,0 +1,214 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package licenses
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Policy allows and denies the licenses of dependencies by their SPDX
+// license identifiers, or patterns of them in the syntax of path.Match,
+// like "GPL-*". The deprecated identifiers of the GNU licenses, like
+// GPL-3.0, stand for the "-only" ones, like GPL-3.0-only.
+type Policy struct {
+	// Allow are the only licenses allowed, if any. Then license files
+	// which aren't identified are not allowed either.
+	Allow []string
+	// Deny are the licenses which are not allowed.
+	Deny []string
+}
+
+// violation returns why the identified licenses of a file are not allowed,
+// or "" if they are. All licenses of a file with several must be allowed.
+func (p Policy) violation(ids []string) string {
+	if len(ids) == 0 && len(p.Allow) > 0 {
+		return "is not identified as any of the allowed licenses"
+	}
+	for _, id := range ids {
+		if matchLicense(p.Deny, id) {
+			return fmt.Sprintf("is licensed under %s, which is denied", id)
+		}
+		if len(p.Allow) > 0 && !matchLicense(p.Allow, id) {
+			return fmt.Sprintf("is licensed under %s, which is not allowed", id)
+		}
+	}
+	return ""
+}
+
+func matchLicense(patterns []string, id string) bool {
+	deprecated := deprecatedID(id)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, id); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, deprecated); ok && deprecated != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// deprecatedIDs maps the deprecated SPDX license identifiers of the corpus
+// to the current ones.
+var deprecatedIDs = map[string]string{
+	"AGPL-3.0": "AGPL-3.0-only",
+	"GPL-2.0":  "GPL-2.0-only",
+	"GPL-3.0":  "GPL-3.0-only",
+	"LGPL-2.1": "LGPL-2.1-only",
+	"LGPL-3.0": "LGPL-3.0-only",
+}
+
+// deprecatedID returns the deprecated SPDX license identifier of a license,
+// or "" if it has none.
+func deprecatedID(id string) string {
+	for deprecated, current := range deprecatedIDs {
+		if current == id {
+			return deprecated
+		}
+	}
+	return ""
+}
+
+// License is a license file of a dependency.
+type License struct {
+	// Path is slash separated and relative to the checked root.
+	Path string
+	// IDs are the SPDX license identifiers of the licenses in the file,
+	// see Identify.
+	IDs []string
+}
+
+// DependencyLicenses returns the license files of the dependencies, sorted
+// by path, with the licenses identified in them: all license files below
+// the root directory, and the known license files, except the ones in
+// CoveredLicensesFileName.
+func (c *Checker) DependencyLicenses() ([]License, error) {
+	files, err := c.licenseFiles(nil)
+	if err != nil {
+		return nil, err
+	}
+	var result []License
+	for _, name := range files {
+		if !strings.Contains(name, "/") {
+			// The license of the repository itself.
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(c.Root, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, License{Path: name, IDs: Identify(string(content))})
+	}
+	return result, nil
+}
+
+// CheckPolicy checks that the licenses of all dependencies are allowed by
+// the Policy.
+func (c *Checker) CheckPolicy() ([]Finding, error) {
+	if len(c.Policy.Allow) == 0 && len(c.Policy.Deny) == 0 {
+		return nil, nil
+	}
+	licenses, err := c.DependencyLicenses()
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	for _, license := range licenses {
+		if violation := c.Policy.violation(license.IDs); violation != "" {
+			findings = append(findings, Finding{
+				Rule:    RuleDeniedLicense,
+				Path:    license.Path,
+				Message: violation,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// groupLicense returns the SPDX license identifier of the corpus named by a
+// group of the checksum file, like "BSD-3-Clause" or "MIT license", or ""
+// if it doesn't name one. Deprecated identifiers, like "GPL-3.0", name the
+// current ones.
+func groupLicense(name string) string {
+	name = strings.TrimSpace(name)
+	if lower := strings.ToLower(name); strings.HasSuffix(lower, " license") {
+		name = strings.TrimSpace(name[:len(name)-len(" license")])
+	}
+	for deprecated, current := range deprecatedIDs {
+		if strings.EqualFold(deprecated, name) {
+			name = current
+		}
+	}
+	for _, license := range corpus {
+		if strings.EqualFold(license.id, name) {
+			return license.id
+		}
+	}
+	return ""
+}
+
+// CheckGroups checks that the license files listed in a group of the
+// checksum file named after a license, like "# BSD-3-Clause", are
+// identified as that license. Files which aren't identified as any license
+// can't be checked, and are accepted.
+func (c *Checker) CheckGroups() ([]Finding, error) {
+	content, err := os.ReadFile(filepath.Join(c.Root, ChecksumFileName))
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	lineNo := 0
+	for _, g := range parseChecksumGroups(string(content)) {
+		lineNo += len(g.header)
+		group := groupLicense(g.name())
+		for _, line := range g.entries {
+			lineNo++
+			entry, ok := parseChecksumLine(line, lineNo)
+			if !ok || group == "" {
+				continue
+			}
+			text, err := os.ReadFile(filepath.Join(c.Root, filepath.FromSlash(entry.Path)))
+			if os.IsNotExist(err) {
+				// Reported by CheckChecksums.
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			ids := Identify(string(text))
+			if len(ids) > 0 && !containsString(ids, group) {
+				findings = append(findings, Finding{
+					Rule: RuleLicenseGroup,
+					Path: entry.Path,
+					Message: fmt.Sprintf("is identified as %s, but listed under %q on line %d of %s",
+						strings.Join(ids, " AND "), g.name(), entry.Line, ChecksumFileName),
+				})
+			}
+		}
+	}
+	return findings, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
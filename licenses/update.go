@@ -0,0 +1,351 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package licenses
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mendersoftware/mendertesting/internal/diff"
+	"github.com/mendersoftware/mendertesting/internal/git"
+)
+
+// UnknownLicenseGroup is the comment heading the entries of new license
+// files whose text doesn't match the license of any listed file, and isn't
+// identified as a single license.
+const UnknownLicenseGroup = "Unknown license"
+
+// ChecksumUpdate is an update of the checksum file.
+type ChecksumUpdate struct {
+	// Added are the entries of license files which weren't listed, and
+	// Unknown the paths of those among them which were put in the
+	// UnknownLicenseGroup.
+	Added   []ChecksumEntry
+	Unknown []string
+	// Removed are the entries of files which don't exist anymore.
+	Removed []ChecksumEntry
+	// Changed are the entries whose files don't match the reviewed
+	// checksum anymore. Their checksums are only updated if accepted.
+	Changed []LicenseChange
+	// Old and New are the contents of the checksum file before and after
+	// the update.
+	Old string
+	New string
+}
+
+// Diff returns the update of the checksum file as a unified diff.
+func (u *ChecksumUpdate) Diff() string {
+	return diff.Unified(ChecksumFileName, u.Old, u.New)
+}
+
+// LicenseChange is a listed license file which changed since its review.
+type LicenseChange struct {
+	// Entry is the reviewed entry, with the line in the old checksum file.
+	Entry ChecksumEntry
+	// Sum is the checksum of the current file.
+	Sum string
+	// Diff is a unified diff from the reviewed license text, as found in
+	// the git history, to the current one. Empty if the reviewed text is
+	// not in the history.
+	Diff string
+}
+
+// checksumGroup is a part of a checksum file: comment and empty lines
+// naming a license, followed by the entries of the files with that license.
+type checksumGroup struct {
+	header  []string
+	entries []string
+}
+
+// name returns the last comment of the header, which names the license.
+func (g *checksumGroup) name() string {
+	for i := len(g.header) - 1; i >= 0; i-- {
+		if name := strings.TrimSpace(strings.TrimPrefix(g.header[i], "#")); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// add adds an entry line, keeping the entries sorted by path if they are.
+func (g *checksumGroup) add(line string) {
+	sorted := sort.SliceIsSorted(g.entries, func(i, j int) bool {
+		return entryPath(g.entries[i]) < entryPath(g.entries[j])
+	})
+	i := len(g.entries)
+	if sorted {
+		i = sort.Search(len(g.entries), func(i int) bool {
+			return entryPath(g.entries[i]) > entryPath(line)
+		})
+	}
+	g.entries = append(g.entries[:i], append([]string{line}, g.entries[i:]...)...)
+}
+
+// entryPath returns the path of an entry line, or "" for a malformed line.
+func entryPath(line string) string {
+	if m := checksumLineRegexp.FindStringSubmatch(line); m != nil {
+		return m[2]
+	}
+	return ""
+}
+
+func parseChecksumGroups(content string) []*checksumGroup {
+	groups := []*checksumGroup{{}}
+	if content == "" {
+		return groups
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		g := groups[len(groups)-1]
+		if line == "" || strings.HasPrefix(line, "#") {
+			if len(g.entries) > 0 {
+				g = &checksumGroup{}
+				groups = append(groups, g)
+			}
+			g.header = append(g.header, line)
+		} else {
+			g.entries = append(g.entries, line)
+		}
+	}
+	return groups
+}
+
+func formatChecksumGroups(groups []*checksumGroup) string {
+	var b strings.Builder
+	for _, g := range groups {
+		for _, line := range append(g.header, g.entries...) {
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// UpdateChecksums updates the checksum file: it adds entries for unlisted
+// license files, and removes the entries of files which don't exist
+// anymore. A new entry is put in the group of a listed file with the same
+// license text, apart from the copyright lines, or else in the group named
+// after its license, see Identify, or the UnknownLicenseGroup. Changed
+// license files are reported, and only get their checksums updated with
+// acceptChanged. Unless dryRun is set, the checksum file is written if
+// anything changed.
+func (c *Checker) UpdateChecksums(
+	ctx context.Context,
+	acceptChanged, dryRun bool,
+) (*ChecksumUpdate, error) {
+	name := filepath.Join(c.Root, ChecksumFileName)
+	old, err := os.ReadFile(name)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	update := &ChecksumUpdate{Old: string(old)}
+	groups := parseChecksumGroups(update.Old)
+
+	listed, licenseGroups, err := c.reviewEntries(ctx, groups, update, acceptChanged)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the unlisted license files.
+	unlisted, err := c.licenseFiles(listed)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range unlisted {
+		content, err := os.ReadFile(filepath.Join(c.Root, filepath.FromSlash(p)))
+		if err != nil {
+			return nil, err
+		}
+		g := groupFor(&groups, licenseGroups, content)
+		if g.name() == UnknownLicenseGroup {
+			update.Unknown = append(update.Unknown, p)
+		}
+		g.add(checksumLine(contentSum(content), p))
+	}
+
+	update.New = formatChecksumGroups(groups)
+	for i, line := range strings.Split(update.New, "\n") {
+		if entry, ok := parseChecksumLine(line, i+1); ok && !listed[entry.Path] {
+			update.Added = append(update.Added, entry)
+		}
+	}
+	if !dryRun && update.New != update.Old {
+		if err := os.WriteFile(name, []byte(update.New), 0644); err != nil {
+			return nil, err
+		}
+	}
+	return update, nil
+}
+
+// reviewEntries removes the entries of missing files from the groups, and
+// adds the changed ones to the update. It returns the listed paths, and the
+// first group of every license text.
+func (c *Checker) reviewEntries(
+	ctx context.Context,
+	groups []*checksumGroup,
+	update *ChecksumUpdate,
+	acceptChanged bool,
+) (map[string]bool, map[string]*checksumGroup, error) {
+	listed := map[string]bool{}
+	licenseGroups := map[string]*checksumGroup{}
+	lineNo := 0
+	for _, g := range groups {
+		lineNo += len(g.header)
+		entries := g.entries[:0]
+		for _, line := range g.entries {
+			lineNo++
+			entry, ok := parseChecksumLine(line, lineNo)
+			if !ok {
+				entries = append(entries, line)
+				continue
+			}
+			content, err := os.ReadFile(filepath.Join(c.Root, filepath.FromSlash(entry.Path)))
+			if os.IsNotExist(err) {
+				update.Removed = append(update.Removed, entry)
+				continue
+			} else if err != nil {
+				return nil, nil, err
+			}
+			listed[entry.Path] = true
+			if _, ok := licenseGroups[licenseKey(content)]; !ok {
+				licenseGroups[licenseKey(content)] = g
+			}
+			if sum := contentSum(content); sum != entry.Sum {
+				change, err := c.licenseChange(ctx, entry, sum, string(content))
+				if err != nil {
+					return nil, nil, err
+				}
+				update.Changed = append(update.Changed, change)
+				if acceptChanged {
+					line = checksumLine(sum, entry.Path)
+				}
+			}
+			entries = append(entries, line)
+		}
+		g.entries = entries
+	}
+	return listed, licenseGroups, nil
+}
+
+func checksumLine(sum, name string) string {
+	return sum + "  " + name
+}
+
+func contentSum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// licenseFiles returns the license files and known license files which are
+// neither listed nor in CoveredLicensesFileName, sorted.
+func (c *Checker) licenseFiles(listed map[string]bool) ([]string, error) {
+	covered, err := readCoveredLicenses(c.Root)
+	if err != nil {
+		return nil, err
+	}
+	files, err := FindLicenseFiles(c.Root)
+	if err != nil {
+		return nil, err
+	}
+	for _, known := range c.KnownLicenseFiles {
+		files = append(files, path.Clean(known))
+	}
+	sort.Strings(files)
+	var unlisted []string
+	for i, name := range files {
+		if (i == 0 || name != files[i-1]) && !listed[name] && !covered[name] {
+			unlisted = append(unlisted, name)
+		}
+	}
+	return unlisted, nil
+}
+
+// groupFor returns the group of a new license file: the group named after
+// its license if it's identified as a single one, or else the first group of
+// a listed file with the same license text, see licenseKey, or the
+// UnknownLicenseGroup. A missing named group is added at the end.
+func groupFor(
+	groups *[]*checksumGroup,
+	licenseGroups map[string]*checksumGroup,
+	content []byte,
+) *checksumGroup {
+	name := UnknownLicenseGroup
+	if ids := Identify(string(content)); len(ids) == 1 {
+		name = ids[0]
+	}
+	if name == UnknownLicenseGroup {
+		if g, ok := licenseGroups[licenseKey(content)]; ok {
+			return g
+		}
+	}
+	for _, g := range *groups {
+		if g.name() == name || groupLicense(g.name()) == name {
+			return g
+		}
+	}
+	g := &checksumGroup{header: []string{"#", "# " + name}}
+	*groups = append(*groups, g)
+	return g
+}
+
+// licenseKey returns the text of a license without its copyright lines, in
+// lower case, with all white space collapsed, to find files with the same
+// license.
+func licenseKey(content []byte) string {
+	var words []string
+	for _, line := range strings.Split(strings.ToLower(string(content)), "\n") {
+		if strings.Contains(line, "copyright") || strings.HasPrefix(strings.TrimSpace(line), "(c)") {
+			continue
+		}
+		words = append(words, strings.Fields(line)...)
+	}
+	return strings.Join(words, " ")
+}
+
+// licenseChange describes a license file which doesn't match its reviewed
+// checksum, with a diff from the latest version in the git history which
+// does.
+func (c *Checker) licenseChange(
+	ctx context.Context,
+	entry ChecksumEntry,
+	sum, content string,
+) (LicenseChange, error) {
+	change := LicenseChange{Entry: entry, Sum: sum}
+	if !git.Succeeds(ctx, c.Root, "rev-parse", "--verify", "--quiet", "HEAD") {
+		// Nothing is committed yet.
+		return change, nil
+	}
+	commits, err := git.Lines(ctx, c.Root, "log", "--format=%H", "--", entry.Path)
+	if err != nil {
+		return change, err
+	}
+	for _, commit := range commits {
+		reviewed, err := git.Output(ctx, c.Root, "show", commit+":./"+entry.Path)
+		if err != nil {
+			// The file was removed in the commit.
+			continue
+		}
+		if contentSum([]byte(reviewed)) == entry.Sum {
+			change.Diff = diff.Unified(entry.Path, reviewed, content)
+			break
+		}
+	}
+	return change, nil
+}
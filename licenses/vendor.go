@@ -0,0 +1,133 @@
+// Copyright 2026 Northern.tech AS
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package licenses
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// Package is a vendored Go package: a directory below vendor with Go files.
+type Package struct {
+	// Dir is slash separated and relative to the checked root.
+	Dir string
+	// License is the slash separated path of the license file covering
+	// the package, in Dir or the nearest parent directory below vendor
+	// which has one. Empty if the package is not covered.
+	License string
+}
+
+// VendorPackages returns all vendored Go packages, sorted by directory, with
+// the license files covering them. Besides the files with common license
+// file names, known license files cover the directory they are in.
+func VendorPackages(root string, knownLicenseFiles []string) ([]Package, error) {
+	if root == "" {
+		root = "."
+	}
+	if info, err := os.Stat(filepath.Join(root, "vendor")); err != nil || !info.IsDir() {
+		return nil, nil
+	}
+	known := make(map[string][]string)
+	for _, name := range knownLicenseFiles {
+		name = path.Clean(name)
+		known[path.Dir(name)] = append(known[path.Dir(name)], name)
+	}
+	for _, names := range known {
+		sort.Strings(names)
+	}
+
+	w := vendorWalk{root: root, known: known}
+	if err := w.walk("vendor", ""); err != nil {
+		return nil, err
+	}
+	return w.packages, nil
+}
+
+// vendorWalk reads every directory below vendor once, passing the license
+// covering a directory on to its subdirectories.
+type vendorWalk struct {
+	root     string
+	known    map[string][]string
+	packages []Package
+}
+
+func (w *vendorWalk) walk(dir, license string) error {
+	entries, err := os.ReadDir(filepath.Join(w.root, filepath.FromSlash(dir)))
+	if err != nil {
+		return err
+	}
+	// vendor itself doesn't cover anything, only the dependencies below
+	// it.
+	if dir != "vendor" {
+		if own := w.license(dir, entries); own != "" {
+			license = own
+		}
+	}
+	var subdirs []string
+	hasGo := false
+	for _, entry := range entries {
+		switch {
+		case entry.IsDir():
+			subdirs = append(subdirs, path.Join(dir, entry.Name()))
+		case entry.Type().IsRegular() && path.Ext(entry.Name()) == ".go":
+			hasGo = true
+		}
+	}
+	if hasGo {
+		w.packages = append(w.packages, Package{Dir: dir, License: license})
+	}
+	for _, subdir := range subdirs {
+		if err := w.walk(subdir, license); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// license returns the license file in the directory, if any.
+func (w *vendorWalk) license(dir string, entries []os.DirEntry) string {
+	for _, entry := range entries {
+		if !entry.IsDir() && IsLicenseFileName(entry.Name()) {
+			return path.Join(dir, entry.Name())
+		}
+	}
+	if names := w.known[dir]; len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// CheckVendorCoverage checks that every vendored Go package has a license
+// file in its own directory or in a parent directory below vendor, and
+// reports each package that doesn't.
+func CheckVendorCoverage(root string, knownLicenseFiles []string) ([]Finding, error) {
+	packages, err := VendorPackages(root, knownLicenseFiles)
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	for _, pkg := range packages {
+		if pkg.License == "" {
+			findings = append(findings, Finding{
+				Rule:    RuleUncovered,
+				Path:    pkg.Dir,
+				Message: "No license file to cover this package",
+			})
+		}
+	}
+	return findings, nil
+}
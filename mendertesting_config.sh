@@ -0,0 +1,36 @@
+#!/bin/bash
+# Copyright 2026 Northern.tech AS
+#
+#    Licensed under the Apache License, Version 2.0 (the "License");
+#    you may not use this file except in compliance with the License.
+#    You may obtain a copy of the License at
+#
+#        http://www.apache.org/licenses/LICENSE-2.0
+#
+#    Unless required by applicable law or agreed to in writing, software
+#    distributed under the License is distributed on an "AS IS" BASIS,
+#    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#    See the License for the specific language governing permissions and
+#    limitations under the License.
+
+# The .mendertesting.yaml policy helpers shared by the check scripts, which
+# source this file next to them.
+
+# Read the policy in .mendertesting.yaml, with the environment on top, if the
+# mendertesting command is in PATH or MENDERTESTING is set to its path. Must
+# be called in the root of the repository.
+read_mendertesting_config() {
+    MENDERTESTING="${MENDERTESTING:-$(which mendertesting 2>/dev/null || true)}"
+    if [ -n "$MENDERTESTING" ]; then
+        MENDERTESTING_CONFIG="$("$MENDERTESTING" config --shell)" || exit 1
+        eval "$MENDERTESTING_CONFIG"
+        if [ -n "$DEBUG_MENDERTESTING" ]; then
+            set -x
+        fi
+    fi
+}
+
+# check_enabled CHECK reports whether CHECK is enabled in .mendertesting.yaml.
+check_enabled() {
+    [ -z "$MENDERTESTING_CHECKS" ] || [[ " $MENDERTESTING_CHECKS " == *" $1 "* ]]
+}